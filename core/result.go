@@ -0,0 +1,71 @@
+// Package core holds the abstractions shared by the immutable and mutable packages.
+package core
+
+import (
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+// Result is the common read side of immutable.Chain[T] and mutable.Wrapper[T].
+// It allows writing helpers that are generic over both packages.
+type Result[T any] interface {
+	Match(success func(T), failure func(error))
+	Unwrap() T
+	IsSuccess() bool
+	IsFailure() bool
+	HasError() error
+	Result() (T, error)
+}
+
+// PtrResult is a Result over a pointer, as implemented by mutable.Wrapper[T].
+type PtrResult[T any] = Result[*T]
+
+var (
+	_ Result[int]    = immutable.Chain[int]{}
+	_ PtrResult[int] = mutable.Wrapper[int]{}
+)
+
+// ValueOr returns the value of r if it has no error, otherwise def.
+// It is a terminal operation: like Result, it runs the deferred actions of r, and a failing action makes it return def.
+func ValueOr[T any](r Result[T], def T) T {
+	val, err := r.Result()
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// CopyPolicy turns the pointer held by a mutable.Wrapper into a value for an immutable.Chain.
+type CopyPolicy[T any] func(*T) (T, error)
+
+// ShallowCopy dereferences p. Slices, maps and pointers inside T stay shared with the wrapper.
+func ShallowCopy[T any](p *T) (T, error) {
+	return *p, nil
+}
+
+// ToMutable converts c into a mutable.Wrapper holding a pointer to a copy of its value.
+// The error of c is carried over; options and the error handler are not.
+// Deferred actions of c run as part of the conversion.
+func ToMutable[T any](c immutable.Chain[T]) mutable.Wrapper[T] {
+	val, err := c.Result()
+	w := mutable.New(&val, nil)
+	return *w.WithError(err)
+}
+
+// ToImmutable converts w into an immutable.Chain, copying its value with policy.
+// A nil policy defaults to ShallowCopy, and a nil value becomes the zero value of T.
+// If the policy fails, its error is stored in the returned chain; it is not called if w failed.
+// The error of w is carried over; options and the error handler are not.
+// Deferred actions of w run as part of the conversion.
+func ToImmutable[T any](w mutable.Wrapper[T], policy CopyPolicy[T]) immutable.Chain[T] {
+	if policy == nil {
+		policy = ShallowCopy[T]
+	}
+
+	ptr, err := w.Result()
+	var val T
+	if ptr != nil && err == nil {
+		val, err = policy(ptr)
+	}
+	return immutable.Wrap(val).WithError(err)
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+type MyStruct struct {
+	Val  int
+	Tags []string
+}
+
+func collect[T any](rs ...Result[T]) (vals []T, errs []error) {
+	for _, r := range rs {
+		r.Match(func(v T) {
+			vals = append(vals, v)
+		}, func(err error) {
+			errs = append(errs, err)
+		})
+	}
+	return vals, errs
+}
+
+func TestResult_GenericOverBothPackages(t *testing.T) {
+	c := immutable.Wrap(MyStruct{Val: 1})
+	cErr := immutable.Wrap(MyStruct{}).WithError(errors.New("fail"))
+
+	vals, errs := collect[MyStruct](c, cErr)
+	if len(vals) != 1 || vals[0].Val != 1 || len(errs) != 1 {
+		t.Fatalf("unexpected collect result: %v, %v", vals, errs)
+	}
+
+	w := mutable.New(&MyStruct{Val: 2}, nil)
+	ptrs, errs := collect[*MyStruct](w)
+	if len(ptrs) != 1 || ptrs[0].Val != 2 || len(errs) != 0 {
+		t.Fatalf("unexpected collect result: %v, %v", ptrs, errs)
+	}
+
+	if got := ValueOr[MyStruct](cErr, MyStruct{Val: 9}); got.Val != 9 {
+		t.Fatalf("expected default value 9, got %d", got.Val)
+	}
+}
+
+func TestToMutable(t *testing.T) {
+	c := immutable.Wrap(MyStruct{Val: 1})
+	w := ToMutable(c)
+
+	w.Then(func(m *MyStruct) (*MyStruct, error) {
+		m.Val = 5
+		return m, nil
+	})
+	if val, _ := c.Result(); val.Val != 1 {
+		t.Fatalf("expected original chain value to stay 1, got %d", val.Val)
+	}
+	if w.Unwrap().Val != 5 {
+		t.Fatalf("expected wrapper value 5, got %d", w.Unwrap().Val)
+	}
+
+	errFail := errors.New("fail")
+	if err := ToMutable(c.WithError(errFail)).HasError(); err != errFail {
+		t.Fatalf("expected error to be carried over, got %v", err)
+	}
+}
+
+func TestToImmutable(t *testing.T) {
+	ms := &MyStruct{Val: 1, Tags: []string{"a"}}
+
+	deep := func(p *MyStruct) (MyStruct, error) {
+		cp := *p
+		cp.Tags = append([]string(nil), p.Tags...)
+		return cp, nil
+	}
+	c := ToImmutable(mutable.New(ms, nil), deep)
+	ms.Tags[0] = "b"
+	if val, _ := c.Result(); val.Tags[0] != "a" {
+		t.Fatalf("expected deep copy to be isolated, got %v", val.Tags)
+	}
+
+	shallow := ToImmutable(mutable.New(ms, nil), nil)
+	ms.Tags[0] = "c"
+	if val, _ := shallow.Result(); val.Tags[0] != "c" {
+		t.Fatalf("expected shallow copy to share slices, got %v", val.Tags)
+	}
+
+	nilVal := ToImmutable(mutable.New[MyStruct](nil, nil), nil)
+	if val, err := nilVal.Result(); err != nil || val.Val != 0 {
+		t.Fatalf("expected zero value for nil pointer, got %v, %v", val, err)
+	}
+
+	errCopy := errors.New("copy failed")
+	failed := ToImmutable(mutable.New(ms, nil), func(*MyStruct) (MyStruct, error) {
+		return MyStruct{}, errCopy
+	})
+	if failed.HasError() != errCopy {
+		t.Fatalf("expected copy error, got %v", failed.HasError())
+	}
+}
+
+func TestPanicError_SharedByBothPackages(t *testing.T) {
+	_, err := mutable.New(&MyStruct{}, nil, mutable.WithPanicSafe()).
+		Then(func(*MyStruct) (*MyStruct, error) { panic("boom") }).
+		Result()
+
+	var pe *immutable.PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Fatalf("expected an *immutable.PanicError from a mutable panic, got %v", err)
+	}
+}
+
+func TestErrNilFunc_SharedByBothPackages(t *testing.T) {
+	_, err := mutable.New(&MyStruct{}, nil, mutable.WithStrict()).Then(nil).Result()
+	if !errors.Is(err, immutable.ErrNilFunc) {
+		t.Fatalf("expected immutable.ErrNilFunc from a mutable nil step, got %v", err)
+	}
+}
+
+func TestReport_SharedByBothPackages(t *testing.T) {
+	_, report := mutable.MapReduceReport([]*mutable.Wrapper[int]{nil}, func(v *int) int { return *v }, func(a, b int) int { return a + b }, 0)
+
+	var r immutable.Report = report
+	var ierr immutable.IndexedError
+	if !errors.As(r.Err(), &ierr) || ierr.Index != 0 {
+		t.Fatalf("expected an immutable.IndexedError at index 0, got %v", r.Err())
+	}
+}
+
+func TestValueOr_RunsDeferredActions(t *testing.T) {
+	ran := false
+	failed := immutable.Wrap(1).Defer(func() error {
+		ran = true
+		return nil
+	}).WithError(errors.New("fail"))
+	if got := ValueOr[int](failed, 9); got != 9 || !ran {
+		t.Fatalf("expected default 9 and the deferred action to run, got %d, %v", got, ran)
+	}
+
+	closeFailed := immutable.Wrap(1).Defer(func() error { return errors.New("close failed") })
+	if got := ValueOr[int](closeFailed, 9); got != 9 {
+		t.Fatalf("expected a failing deferred action to give the default, got %d", got)
+	}
+}
+
+func TestToImmutable_SkipsPolicyOnFailure(t *testing.T) {
+	errFail := errors.New("fail")
+	called := false
+	c := ToImmutable(*mutable.Lift(&MyStruct{Val: 1}, nil).WithError(errFail), func(p *MyStruct) (MyStruct, error) {
+		called = true
+		return *p, nil
+	})
+	if called {
+		t.Fatal("expected the copy policy not to be called for a failed wrapper")
+	}
+	if val, err := c.Result(); err != errFail || val.Val != 0 {
+		t.Fatalf("expected the wrapper error and a zero value, got %v, %v", val, err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package dag runs steps that depend on each other's outputs as a directed acyclic graph.
+//
+// Steps are added to a Graph with Add, Add1, Add2 and Add3, which return a typed Node handle
+// for the step output. Dependencies are given as Node handles, so their types are checked by the compiler;
+// Ref creates a handle by name for a step that is added later, checked by Build instead.
+// Build validates the graph, and Plan.Run executes it, running independent steps concurrently.
+// Each node outcome is read back as an immutable.Chain with Get.
+package dag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+// Node is a typed handle on the output of a step.
+type Node[T any] struct {
+	name string
+}
+
+// Ref returns a handle on the step named name, which may be added after the steps depending on it.
+// Its existence and type are checked by Build.
+func Ref[T any](name string) Node[T] {
+	return Node[T]{name: name}
+}
+
+// Name returns the name of the step.
+func (n Node[T]) Name() string {
+	return n.name
+}
+
+// dep is a reference from a step to one of its dependencies, with the type it expects.
+type dep struct {
+	name string
+	typ  reflect.Type
+}
+
+func depOf[T any](n Node[T]) dep {
+	return dep{name: n.name, typ: reflect.TypeFor[T]()}
+}
+
+type node struct {
+	name string
+	typ  reflect.Type
+	deps []dep
+	fn   func(ctx context.Context, args []any) (any, error)
+}
+
+// Graph collects steps before they are validated by Build. It is not safe for concurrent use.
+type Graph struct {
+	nodes map[string]*node
+	order []string
+	errs  []error
+}
+
+// New returns an empty Graph.
+func New() *Graph {
+	return &Graph{nodes: make(map[string]*node)}
+}
+
+// Add adds a step without dependencies.
+// A duplicate name or a nil fn is reported by Build.
+func Add[T any](g *Graph, name string, fn func(context.Context) (T, error)) Node[T] {
+	var run func(context.Context, []any) (any, error)
+	if fn != nil {
+		run = func(ctx context.Context, _ []any) (any, error) {
+			return fn(ctx)
+		}
+	}
+	return add[T](g, name, nil, run)
+}
+
+// Add1 adds a step depending on the output of a.
+func Add1[A, T any](g *Graph, name string, a Node[A], fn func(context.Context, A) (T, error)) Node[T] {
+	var run func(context.Context, []any) (any, error)
+	if fn != nil {
+		run = func(ctx context.Context, args []any) (any, error) {
+			return fn(ctx, as[A](args[0]))
+		}
+	}
+	return add[T](g, name, []dep{depOf(a)}, run)
+}
+
+// Add2 adds a step depending on the outputs of a and b.
+func Add2[A, B, T any](g *Graph, name string, a Node[A], b Node[B], fn func(context.Context, A, B) (T, error)) Node[T] {
+	var run func(context.Context, []any) (any, error)
+	if fn != nil {
+		run = func(ctx context.Context, args []any) (any, error) {
+			return fn(ctx, as[A](args[0]), as[B](args[1]))
+		}
+	}
+	return add[T](g, name, []dep{depOf(a), depOf(b)}, run)
+}
+
+// Add3 adds a step depending on the outputs of a, b and c.
+func Add3[A, B, C, T any](g *Graph, name string, a Node[A], b Node[B], c Node[C], fn func(context.Context, A, B, C) (T, error)) Node[T] {
+	var run func(context.Context, []any) (any, error)
+	if fn != nil {
+		run = func(ctx context.Context, args []any) (any, error) {
+			return fn(ctx, as[A](args[0]), as[B](args[1]), as[C](args[2]))
+		}
+	}
+	return add[T](g, name, []dep{depOf(a), depOf(b), depOf(c)}, run)
+}
+
+func add[T any](g *Graph, name string, deps []dep, fn func(context.Context, []any) (any, error)) Node[T] {
+	switch {
+	case fn == nil:
+		g.errs = append(g.errs, fmt.Errorf("node %q: %w", name, immutable.ErrNilFunc))
+	case g.nodes[name] != nil:
+		g.errs = append(g.errs, fmt.Errorf("node %q: %w", name, ErrDuplicateNode))
+	default:
+		g.nodes[name] = &node{name: name, typ: reflect.TypeFor[T](), deps: deps, fn: fn}
+		g.order = append(g.order, name)
+	}
+	return Node[T]{name: name}
+}
+
+// as converts a dependency output back to its static type; a nil interface gives the zero value.
+func as[T any](v any) T {
+	t, _ := v.(T)
+	return t
+}
+
+// Plan is a validated graph, with its steps in dependency order. It can be run several times.
+type Plan struct {
+	nodes []*node
+}
+
+// Build validates the graph: duplicate names, nil functions, unknown dependencies,
+// dependency types and cycles are all reported, joined in a single error.
+func (g *Graph) Build() (*Plan, error) {
+	errs := append([]error(nil), g.errs...)
+	for _, name := range g.order {
+		n := g.nodes[name]
+		for _, d := range n.deps {
+			target, ok := g.nodes[d.name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("node %q depends on %q: %w", n.name, d.name, ErrUnknownNode))
+				continue
+			}
+			if target.typ != d.typ {
+				errs = append(errs, fmt.Errorf("node %q: %w", n.name, &TypeMismatchError{Node: d.name, Want: d.typ, Got: target.typ}))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	sorted, err := g.sort()
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{nodes: sorted}, nil
+}
+
+// sort orders the nodes so that each comes after its dependencies, reporting the first cycle found.
+func (g *Graph) sort() ([]*node, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(g.nodes))
+	sorted := make([]*node, 0, len(g.nodes))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			for i, p := range path {
+				if p == name {
+					return &CycleError{Path: append(append([]string(nil), path[i:]...), name)}
+				}
+			}
+		}
+		state[name] = visiting
+		path = append(path, name)
+		n := g.nodes[name]
+		for _, d := range n.deps {
+			if err := visit(d.name); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		sorted = append(sorted, n)
+		return nil
+	}
+
+	for _, name := range g.order {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,74 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+func constant[T any](v T) func(context.Context) (T, error) {
+	return func(context.Context) (T, error) { return v, nil }
+}
+
+func TestBuild_DuplicateAndNil(t *testing.T) {
+	g := New()
+	Add(g, "a", constant(1))
+	Add(g, "a", constant(2))
+	Add[int](g, "b", nil)
+
+	_, err := g.Build()
+	if !errors.Is(err, ErrDuplicateNode) || !errors.Is(err, immutable.ErrNilFunc) {
+		t.Fatalf("expected duplicate and nil function errors, got %v", err)
+	}
+}
+
+func TestBuild_UnknownDependency(t *testing.T) {
+	g := New()
+	Add1(g, "b", Ref[int]("missing"), func(_ context.Context, v int) (int, error) { return v, nil })
+
+	if _, err := g.Build(); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode, got %v", err)
+	}
+}
+
+func TestBuild_TypeMismatch(t *testing.T) {
+	g := New()
+	Add1(g, "b", Ref[string]("a"), func(_ context.Context, s string) (int, error) { return len(s), nil })
+	Add(g, "a", constant(1))
+
+	_, err := g.Build()
+	var tm *TypeMismatchError
+	if !errors.As(err, &tm) || tm.Node != "a" || tm.Got.String() != "int" || tm.Want.String() != "string" {
+		t.Fatalf("expected *TypeMismatchError on a, got %v", err)
+	}
+}
+
+func TestBuild_Cycle(t *testing.T) {
+	g := New()
+	id := func(_ context.Context, v int) (int, error) { return v, nil }
+	Add1(g, "a", Ref[int]("c"), id)
+	Add1(g, "b", Ref[int]("a"), id)
+	Add1(g, "c", Ref[int]("b"), id)
+
+	_, err := g.Build()
+	var cycle *CycleError
+	if !errors.As(err, &cycle) || len(cycle.Path) != 4 || cycle.Path[0] != cycle.Path[3] {
+		t.Fatalf("expected a closed cycle path, got %v", err)
+	}
+}
+
+func TestBuild_ForwardRef(t *testing.T) {
+	g := New()
+	double := Add1(g, "double", Ref[int]("base"), func(_ context.Context, v int) (int, error) { return v * 2, nil })
+	Add(g, "base", constant(21))
+
+	plan, err := g.Build()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := Get(plan.Run(context.Background()), double).Unwrap(); got != 42 {
+		t.Fatalf("expected 42, got %d", got)
+	}
+}
@@ -0,0 +1,51 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrDuplicateNode is reported by Build when two steps share a name.
+	ErrDuplicateNode = errors.New("duplicate node")
+	// ErrUnknownNode is reported by Build for a dependency on a step that was never added,
+	// and by Get for a node that is not part of the run.
+	ErrUnknownNode = errors.New("unknown node")
+)
+
+// CycleError is reported by Build when the dependencies form a cycle.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle: " + strings.Join(e.Path, " -> ")
+}
+
+// TypeMismatchError is reported when a node is referenced with a type other than its output type.
+type TypeMismatchError struct {
+	Node string
+	Want reflect.Type
+	Got  reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("node %q produces %v, referenced as %v", e.Node, e.Got, e.Want)
+}
+
+// DependencyError is the error of a node skipped because one of its dependencies failed.
+type DependencyError struct {
+	Node string
+	Dep  string
+	Err  error
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("node %q skipped: dependency %q failed: %v", e.Node, e.Dep, e.Err)
+}
+
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,138 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+// RunOption configures Plan.Run.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	concurrency int
+}
+
+// WithConcurrency limits the number of steps running at the same time. Values lower than one mean no limit.
+func WithConcurrency(n int) RunOption {
+	return func(o *runOptions) {
+		o.concurrency = n
+	}
+}
+
+// outcome is the result of a node, published by closing done.
+type outcome struct {
+	val  any
+	err  error
+	done chan struct{}
+}
+
+// Results holds the outcome of every node of a run.
+type Results struct {
+	order []string
+	outs  map[string]*outcome
+}
+
+// Run executes the plan and waits for every step to finish.
+// Each step starts as soon as all its dependencies succeeded, concurrently with the other ready steps.
+// A step whose dependency failed is not run, and fails with a *DependencyError.
+// Steps not started when ctx is cancelled fail with the context error.
+// A panic in a step is recovered and stored as an *immutable.PanicError.
+func (p *Plan) Run(ctx context.Context, opts ...RunOption) *Results {
+	var o runOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	var sem chan struct{}
+	if o.concurrency > 0 {
+		sem = make(chan struct{}, o.concurrency)
+	}
+
+	r := &Results{outs: make(map[string]*outcome, len(p.nodes))}
+	for _, n := range p.nodes {
+		r.order = append(r.order, n.name)
+		r.outs[n.name] = &outcome{done: make(chan struct{})}
+	}
+	for _, n := range p.nodes {
+		go r.run(ctx, n, sem)
+	}
+	for _, out := range r.outs {
+		<-out.done
+	}
+	return r
+}
+
+// run waits for the dependencies of n, then executes it and publishes its outcome.
+func (r *Results) run(ctx context.Context, n *node, sem chan struct{}) {
+	out := r.outs[n.name]
+	defer close(out.done)
+
+	args := make([]any, len(n.deps))
+	for i, d := range n.deps {
+		dep := r.outs[d.name]
+		<-dep.done
+		if dep.err != nil {
+			out.err = &DependencyError{Node: n.name, Dep: d.name, Err: dep.err}
+			return
+		}
+		args[i] = dep.val
+	}
+
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-ctx.Done():
+			out.err = ctx.Err()
+			return
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		out.err = err
+		return
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			out.val, out.err = nil, &immutable.PanicError{Value: rec, Stack: debug.Stack()}
+		}
+	}()
+	out.val, out.err = n.fn(ctx, args)
+}
+
+// Get returns the outcome of node n as a chain.
+// A node that is not part of the run gives ErrUnknownNode, and a mistyped Ref a *TypeMismatchError.
+func Get[T any](r *Results, n Node[T]) immutable.Chain[T] {
+	var zero T
+	out, ok := r.outs[n.name]
+	if !ok {
+		return immutable.Wrap(zero).WithError(fmt.Errorf("node %q: %w", n.name, ErrUnknownNode))
+	}
+	if out.err != nil {
+		return immutable.Wrap(zero).WithError(out.err)
+	}
+	v, ok := out.val.(T)
+	if !ok && out.val != nil {
+		return immutable.Wrap(zero).WithError(&TypeMismatchError{Node: n.name, Want: reflect.TypeFor[T](), Got: reflect.TypeOf(out.val)})
+	}
+	return immutable.Wrap(v)
+}
+
+// Err joins the errors of the failed nodes, in dependency order.
+// Nodes skipped because of a failed dependency are left out, so only root causes are reported.
+func (r *Results) Err() error {
+	var errs []error
+	for _, name := range r.order {
+		err := r.outs[name].err
+		if _, skipped := err.(*DependencyError); err != nil && !skipped {
+			errs = append(errs, fmt.Errorf("node %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
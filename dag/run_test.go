@@ -0,0 +1,161 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+func TestRun_Diamond(t *testing.T) {
+	g := New()
+	base := Add(g, "base", constant(3))
+	left := Add1(g, "left", base, func(_ context.Context, v int) (int, error) { return v + 1, nil })
+	right := Add1(g, "right", base, func(_ context.Context, v int) (string, error) { return strconv.Itoa(v), nil })
+	joined := Add2(g, "joined", left, right, func(_ context.Context, l int, r string) (string, error) {
+		return strconv.Itoa(l) + "/" + r, nil
+	})
+
+	plan, err := g.Build()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	res := plan.Run(context.Background())
+	got, err := Get(res, joined).Result()
+	if err != nil || got != "4/3" {
+		t.Fatalf("expected 4/3, got %q, %v", got, err)
+	}
+	if res.Err() != nil {
+		t.Fatalf("expected no errors, got %v", res.Err())
+	}
+}
+
+func TestRun_Concurrent(t *testing.T) {
+	// Each step waits until all three have started, which only happens if they run concurrently.
+	var started atomic.Int32
+	all := make(chan struct{})
+	step := func(context.Context) (int, error) {
+		if started.Add(1) == 3 {
+			close(all)
+		}
+		select {
+		case <-all:
+			return 1, nil
+		case <-time.After(5 * time.Second):
+			return 0, errors.New("the independent steps did not run concurrently")
+		}
+	}
+	g := New()
+	a := Add(g, "a", step)
+	b := Add(g, "b", step)
+	c := Add(g, "c", step)
+	sum := Add3(g, "sum", a, b, c, func(_ context.Context, x, y, z int) (int, error) { return x + y + z, nil })
+
+	plan, _ := g.Build()
+	if got, err := Get(plan.Run(context.Background()), sum).Result(); err != nil || got != 3 {
+		t.Fatalf("expected 3, got %d, %v", got, err)
+	}
+}
+
+func TestRun_ConcurrencyLimit(t *testing.T) {
+	g := New()
+	var running, peak atomic.Int32
+	slow := func(context.Context) (int, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return 1, nil
+	}
+	a := Add(g, "a", slow)
+	b := Add(g, "b", slow)
+	c := Add(g, "c", slow)
+	sum := Add3(g, "sum", a, b, c, func(_ context.Context, x, y, z int) (int, error) { return x + y + z, nil })
+
+	plan, _ := g.Build()
+	if got := Get(plan.Run(context.Background(), WithConcurrency(1)), sum).Unwrap(); got != 3 {
+		t.Fatalf("expected 3, got %d", got)
+	}
+	if peak.Load() != 1 {
+		t.Fatalf("expected at most one step at a time, peak was %d", peak.Load())
+	}
+}
+
+func TestRun_ShortCircuit(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	var ran atomic.Bool
+
+	g := New()
+	fetch := Add(g, "fetch", func(context.Context) (int, error) { return 0, errFetch })
+	other := Add(g, "other", constant("ok"))
+	parse := Add1(g, "parse", fetch, func(_ context.Context, v int) (int, error) {
+		ran.Store(true)
+		return v, nil
+	})
+	store := Add2(g, "store", parse, other, func(_ context.Context, v int, _ string) (int, error) {
+		ran.Store(true)
+		return v, nil
+	})
+
+	plan, _ := g.Build()
+	res := plan.Run(context.Background())
+	if ran.Load() {
+		t.Fatal("expected dependents of a failed step not to run")
+	}
+
+	_, err := Get(res, store).Result()
+	var derr *DependencyError
+	if !errors.As(err, &derr) || derr.Dep != "parse" || !errors.Is(err, errFetch) {
+		t.Fatalf("expected a DependencyError wrapping the fetch error, got %v", err)
+	}
+	if Get(res, other).IsFailure() {
+		t.Fatal("expected the independent step to succeed")
+	}
+	if err := res.Err(); !errors.Is(err, errFetch) || errors.As(err, &derr) {
+		t.Fatalf("expected only the root cause, got %v", err)
+	}
+}
+
+func TestRun_PanicAndCancel(t *testing.T) {
+	g := New()
+	boom := Add(g, "boom", func(context.Context) (int, error) { panic("boom") })
+	plan, _ := g.Build()
+
+	var pe *immutable.PanicError
+	if _, err := Get(plan.Run(context.Background()), boom).Result(); !errors.As(err, &pe) {
+		t.Fatalf("expected *immutable.PanicError, got %v", err)
+	}
+
+	g = New()
+	a := Add(g, "a", constant(1))
+	plan, _ = g.Build()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Get(plan.Run(ctx), a).Result(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGet_Unknown(t *testing.T) {
+	g := New()
+	Add(g, "a", constant(1))
+	plan, _ := g.Build()
+	res := plan.Run(context.Background())
+
+	if _, err := Get(res, Ref[int]("b")).Result(); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode, got %v", err)
+	}
+	var tm *TypeMismatchError
+	if _, err := Get(res, Ref[string]("a")).Result(); !errors.As(err, &tm) {
+		t.Fatalf("expected *TypeMismatchError, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+// Package eventsource stores aggregates as streams of events.
+//
+// An aggregate *A is rebuilt by folding its events through mutable.Wrapper.Then steps running the
+// event handler, so errors flow through the wrapper error handler like in any other pipeline.
+// Commands inspect the current state and return the new events as an immutable.Chain.
+// Saving appends them with optimistic concurrency: a conflicting writer fails with a *ConflictError.
+// Snapshots can be taken every N events to bound the number of events replayed on load.
+package eventsource
+
+import (
+	"fmt"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+	"github.com/KeibiSoft/go-fp/internal/deep"
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+// Handler applies an event to an aggregate, as a Wrapper.Then step.
+type Handler[A, E any] func(*A, E) (*A, error)
+
+// Command inspects an aggregate, which it must not modify, and returns the events it produces.
+type Command[A, E any] func(*A) immutable.Chain[[]E]
+
+// SnapshotError is returned by Save when the events were stored but the snapshot could not be.
+type SnapshotError struct {
+	ID      string
+	Version int
+	Err     error
+}
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("aggregate %q: snapshot at version %d: %v", e.ID, e.Version, e.Err)
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a Repository.
+type Option[A any] func(*options[A])
+
+type options[A any] struct {
+	snapshots SnapshotStore[A]
+	every     int
+	initial   func() *A
+}
+
+// WithSnapshots saves a snapshot to s whenever a save crosses a multiple of every events,
+// and starts loading from the latest snapshot. Snapshots are deep copies of the state.
+func WithSnapshots[A any](s SnapshotStore[A], every int) Option[A] {
+	return func(o *options[A]) {
+		if s != nil && every > 0 {
+			o.snapshots, o.every = s, every
+		}
+	}
+}
+
+// WithInitial sets the state of an aggregate without events, a zero A by default.
+func WithInitial[A any](fn func() *A) Option[A] {
+	return func(o *options[A]) {
+		if fn != nil {
+			o.initial = fn
+		}
+	}
+}
+
+// Repository loads and saves aggregates of type A with events of type E. It is safe for concurrent use.
+type Repository[A, E any] struct {
+	store      Store[E]
+	apply      Handler[A, E]
+	errHandler func(error) error
+	opts       options[A]
+}
+
+// NewRepository returns a Repository storing events in store and applying them with apply.
+// errHandler is given to the wrappers of the aggregates, see mutable.New.
+func NewRepository[A, E any](store Store[E], apply Handler[A, E], errHandler func(error) error, opts ...Option[A]) *Repository[A, E] {
+	o := options[A]{initial: func() *A { return new(A) }}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return &Repository[A, E]{store: store, apply: apply, errHandler: errHandler, opts: o}
+}
+
+// Aggregate is a loaded aggregate with the events produced since. It is not safe for concurrent use.
+type Aggregate[A, E any] struct {
+	id      string
+	version int
+	state   mutable.Wrapper[A]
+	pending []E
+	apply   Handler[A, E]
+}
+
+// ID returns the ID of the aggregate.
+func (a *Aggregate[A, E]) ID() string {
+	return a.id
+}
+
+// Version returns the number of stored events the aggregate was built from.
+func (a *Aggregate[A, E]) Version() int {
+	return a.version
+}
+
+// State returns the current state, including the pending events.
+func (a *Aggregate[A, E]) State() mutable.Wrapper[A] {
+	return a.state
+}
+
+// Pending returns the events produced since the aggregate was loaded or saved.
+func (a *Aggregate[A, E]) Pending() []E {
+	return append([]E(nil), a.pending...)
+}
+
+// Execute runs cmd on the current state and applies the events it returns.
+// The events are kept pending until Save, unless cmd or the handler fails, which fails the state.
+// An aggregate whose state failed ignores further commands.
+func (a *Aggregate[A, E]) Execute(cmd Command[A, E]) *Aggregate[A, E] {
+	if a.state.IsFailure() {
+		return a
+	}
+	var events []E
+	w := a.state.Then(func(s *A) (*A, error) {
+		if cmd == nil {
+			return s, immutable.ErrNilFunc
+		}
+		var err error
+		events, err = cmd(s).Result()
+		return s, err
+	})
+	for _, ev := range events {
+		w = w.Then(a.step(ev))
+	}
+	if w.IsSuccess() {
+		a.pending = append(a.pending, events...)
+	}
+	a.state = w
+	return a
+}
+
+// step returns the Then step applying ev.
+func (a *Aggregate[A, E]) step(ev E) func(*A) (*A, error) {
+	return func(s *A) (*A, error) {
+		if a.apply == nil {
+			return s, immutable.ErrNilFunc
+		}
+		return a.apply(s, ev)
+	}
+}
+
+// Load rebuilds the aggregate id from its latest snapshot, if any, and the events stored after it.
+// Loading errors are stored in the state of the returned aggregate.
+func (r *Repository[A, E]) Load(id string) *Aggregate[A, E] {
+	a := &Aggregate[A, E]{id: id, apply: r.apply}
+	s := r.opts.initial()
+	if r.opts.snapshots != nil {
+		snap, ok, err := r.opts.snapshots.LoadSnapshot(id)
+		if err != nil {
+			a.state = r.failed(s, &SnapshotError{ID: id, Err: err})
+			return a
+		}
+		if ok {
+			state := deep.Copy(snap.State)
+			s, a.version = &state, snap.Version
+		}
+	}
+
+	envs, err := r.store.Load(id, a.version)
+	if err != nil {
+		a.state = r.failed(s, err)
+		return a
+	}
+	a.state = mutable.New(s, r.errHandler)
+	for _, env := range envs {
+		a.state = a.state.Then(a.step(env.Event))
+		a.version = env.Version
+	}
+	return a
+}
+
+// Save appends the pending events of a, expecting the store to be at the version a was loaded at.
+// A failed state is not saved, and its error is returned.
+// If the store changed in between, it returns a *ConflictError and a must be reloaded.
+// If a snapshot is due but cannot be saved, it returns a *SnapshotError although the events were stored.
+func (r *Repository[A, E]) Save(a *Aggregate[A, E]) error {
+	if err := a.state.HasError(); err != nil {
+		return err
+	}
+	if len(a.pending) == 0 {
+		return nil
+	}
+	version, err := r.store.Append(a.id, a.version, a.pending)
+	if err != nil {
+		return err
+	}
+	previous := a.version
+	a.version, a.pending = version, nil
+
+	if r.opts.snapshots == nil || version/r.opts.every == previous/r.opts.every {
+		return nil
+	}
+	s, err := a.state.Result()
+	if err == nil {
+		err = r.opts.snapshots.SaveSnapshot(a.id, Snapshot[A]{Version: version, State: deep.Copy(*s)})
+	}
+	if err != nil {
+		return &SnapshotError{ID: a.id, Version: version, Err: err}
+	}
+	return nil
+}
+
+// Handle loads the aggregate id, executes cmd and saves the produced events.
+// The returned state holds any error, including a *ConflictError from a concurrent writer.
+// A snapshot failure does not fail the command, since its events are stored.
+func (r *Repository[A, E]) Handle(id string, cmd Command[A, E]) mutable.Wrapper[A] {
+	a := r.Load(id).Execute(cmd)
+	err := r.Save(a)
+	if _, snap := err.(*SnapshotError); err != nil && !snap && a.state.IsSuccess() {
+		return a.state.Then(func(s *A) (*A, error) {
+			return s, err
+		})
+	}
+	return a.state
+}
+
+// failed returns a wrapper holding s and err, passed through the error handler.
+func (r *Repository[A, E]) failed(s *A, err error) mutable.Wrapper[A] {
+	return mutable.New(s, r.errHandler).Then(func(s *A) (*A, error) {
+		return s, err
+	})
+}
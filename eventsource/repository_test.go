@@ -0,0 +1,156 @@
+package eventsource
+
+import (
+	"errors"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+type Account struct {
+	Balance int
+	History []int
+}
+
+type Event struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+}
+
+var errInsufficient = errors.New("insufficient funds")
+
+func applyEvent(a *Account, ev Event) (*Account, error) {
+	switch ev.Type {
+	case "deposited":
+		a.Balance += ev.Amount
+	case "withdrawn":
+		a.Balance -= ev.Amount
+	default:
+		return a, errors.New("unknown event " + ev.Type)
+	}
+	a.History = append(a.History, a.Balance)
+	return a, nil
+}
+
+func deposit(n int) Command[Account, Event] {
+	return func(*Account) immutable.Chain[[]Event] {
+		return immutable.Wrap([]Event{{Type: "deposited", Amount: n}})
+	}
+}
+
+func withdraw(n int) Command[Account, Event] {
+	return func(a *Account) immutable.Chain[[]Event] {
+		return immutable.Wrap([]Event{{Type: "withdrawn", Amount: n}}).
+			Filter(func([]Event) bool { return a.Balance >= n }, errInsufficient)
+	}
+}
+
+func TestRepository_ExecuteSave(t *testing.T) {
+	repo := NewRepository(NewMemoryStore[Event](), applyEvent, nil)
+
+	a := repo.Load("acc").Execute(deposit(10)).Execute(withdraw(4))
+	if len(a.Pending()) != 2 {
+		t.Fatalf("expected 2 pending events, got %v", a.Pending())
+	}
+	if err := repo.Save(a); err != nil || a.Version() != 2 {
+		t.Fatalf("expected version 2, got %d, %v", a.Version(), err)
+	}
+
+	got, err := repo.Load("acc").State().Result()
+	if err != nil || got.Balance != 6 || len(got.History) != 2 {
+		t.Fatalf("expected balance 6 rebuilt from 2 events, got %+v, %v", got, err)
+	}
+}
+
+func TestRepository_CommandError(t *testing.T) {
+	var handled error
+	repo := NewRepository(NewMemoryStore[Event](), applyEvent, func(err error) error {
+		handled = err
+		return err
+	})
+
+	if _, err := repo.Handle("acc", withdraw(1)).Result(); !errors.Is(err, errInsufficient) {
+		t.Fatalf("expected errInsufficient, got %v", err)
+	}
+	if !errors.Is(handled, errInsufficient) {
+		t.Fatalf("expected the error handler to see the command error, got %v", handled)
+	}
+	if v := repo.Load("acc").Version(); v != 0 {
+		t.Fatalf("expected no stored event, got version %d", v)
+	}
+}
+
+func TestRepository_Conflict(t *testing.T) {
+	repo := NewRepository(NewMemoryStore[Event](), applyEvent, nil)
+	repo.Handle("acc", deposit(10))
+
+	first := repo.Load("acc").Execute(withdraw(10))
+	second := repo.Load("acc").Execute(withdraw(10))
+	if err := repo.Save(first); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err := repo.Save(second)
+	var conflict *ConflictError
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+		t.Fatalf("expected a conflict at version 1, got %v", err)
+	}
+
+	// Reloading sees the first withdrawal, so the command is rejected.
+	if _, err := repo.Handle("acc", withdraw(10)).Result(); !errors.Is(err, errInsufficient) {
+		t.Fatalf("expected errInsufficient after reload, got %v", err)
+	}
+}
+
+// countingStore counts the events loaded from a store.
+type countingStore struct {
+	Store[Event]
+	loaded int
+}
+
+func (s *countingStore) Load(id string, after int) ([]Envelope[Event], error) {
+	envs, err := s.Store.Load(id, after)
+	s.loaded += len(envs)
+	return envs, err
+}
+
+func TestRepository_Snapshots(t *testing.T) {
+	store := &countingStore{Store: NewMemoryStore[Event]()}
+	snaps := NewMemorySnapshotStore[Account]()
+	repo := NewRepository(store, applyEvent, nil, WithSnapshots(snaps, 3))
+
+	for range 4 {
+		repo.Handle("acc", deposit(1))
+	}
+	snap, ok, _ := snaps.LoadSnapshot("acc")
+	if !ok || snap.Version != 3 || snap.State.Balance != 3 {
+		t.Fatalf("expected a snapshot at version 3, got %+v, %v", snap, ok)
+	}
+
+	store.loaded = 0
+	a := repo.Load("acc")
+	got := a.State().Unwrap()
+	if got.Balance != 4 || a.Version() != 4 || store.loaded != 1 {
+		t.Fatalf("expected balance 4 from the snapshot and 1 event, got %+v, version %d, %d loaded", got, a.Version(), store.loaded)
+	}
+
+	// The snapshot is a deep copy, unaffected by the live aggregate.
+	got.History[0] = 100
+	if snap, _, _ := snaps.LoadSnapshot("acc"); snap.State.History[0] != 1 {
+		t.Fatalf("expected the snapshot not to share memory with the aggregate, got %v", snap.State.History)
+	}
+}
+
+func TestRepository_HandlerError(t *testing.T) {
+	store := NewMemoryStore[Event]()
+	store.Append("acc", 0, []Event{{Type: "closed"}})
+	repo := NewRepository(store, applyEvent, nil)
+
+	a := repo.Load("acc")
+	if a.State().IsSuccess() {
+		t.Fatal("expected the unknown event to fail the state")
+	}
+	if err := repo.Save(a.Execute(deposit(1))); err == nil {
+		t.Fatal("expected a failed aggregate not to be saved")
+	}
+}
@@ -0,0 +1,114 @@
+package eventsource
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Snapshot is the state of an aggregate after its first Version events.
+type Snapshot[A any] struct {
+	Version int `json:"version"`
+	State   A   `json:"state"`
+}
+
+// SnapshotStore keeps the latest snapshot of each aggregate.
+type SnapshotStore[A any] interface {
+	LoadSnapshot(id string) (Snapshot[A], bool, error)
+	SaveSnapshot(id string, s Snapshot[A]) error
+}
+
+// MemorySnapshotStore keeps snapshots in memory. It is safe for concurrent use.
+type MemorySnapshotStore[A any] struct {
+	mu    sync.RWMutex
+	snaps map[string]Snapshot[A]
+}
+
+// NewMemorySnapshotStore returns an empty MemorySnapshotStore.
+func NewMemorySnapshotStore[A any]() *MemorySnapshotStore[A] {
+	return &MemorySnapshotStore[A]{snaps: make(map[string]Snapshot[A])}
+}
+
+func (s *MemorySnapshotStore[A]) LoadSnapshot(id string) (Snapshot[A], bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap, ok := s.snaps[id]
+	return snap, ok, nil
+}
+
+func (s *MemorySnapshotStore[A]) SaveSnapshot(id string, snap Snapshot[A]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snaps[id] = snap
+	return nil
+}
+
+// FileSnapshotStore keeps the snapshot of each aggregate as a JSON file under its directory.
+type FileSnapshotStore[A any] struct {
+	dir string
+}
+
+// NewFileSnapshotStore returns a FileSnapshotStore keeping its files under dir, created on first use.
+func NewFileSnapshotStore[A any](dir string) *FileSnapshotStore[A] {
+	return &FileSnapshotStore[A]{dir: dir}
+}
+
+func (s *FileSnapshotStore[A]) path(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+".snapshot.json")
+}
+
+func (s *FileSnapshotStore[A]) LoadSnapshot(id string) (Snapshot[A], bool, error) {
+	var snap Snapshot[A]
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return snap, false, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &snap)
+	}
+	return snap, err == nil, err
+}
+
+// SaveSnapshot writes the snapshot to a synced temporary file and renames it, so a crash never leaves a partial one.
+func (s *FileSnapshotStore[A]) SaveSnapshot(id string, snap Snapshot[A]) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(id)); err != nil {
+		return err
+	}
+	return syncDir(s.dir)
+}
+
+// syncDir flushes the entries of dir to disk, so a rename into it survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
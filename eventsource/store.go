@@ -0,0 +1,200 @@
+package eventsource
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Envelope is a stored event, with the aggregate it belongs to and its version in the aggregate stream.
+// Versions start at one and have no gaps.
+type Envelope[E any] struct {
+	ID      string    `json:"id"`
+	Version int       `json:"version"`
+	At      time.Time `json:"at"`
+	Event   E         `json:"event"`
+}
+
+// Store is an append-only event store with optimistic concurrency.
+type Store[E any] interface {
+	// Load returns the events of the aggregate id with a version greater than after, in order.
+	Load(id string, after int) ([]Envelope[E], error)
+	// Append adds events to the aggregate id if its current version is expected,
+	// and returns the new version. Otherwise it fails with a *ConflictError.
+	Append(id string, expected int, events []E) (int, error)
+}
+
+// ErrConflict is matched by every *ConflictError.
+var ErrConflict = errors.New("concurrent modification")
+
+// ConflictError is returned by Append when the aggregate changed since it was loaded.
+type ConflictError struct {
+	ID       string
+	Expected int
+	Actual   int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("aggregate %q: expected version %d, found %d: %v", e.ID, e.Expected, e.Actual, ErrConflict)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// envelopes returns the envelopes of events, numbered after version.
+func envelopes[E any](id string, version int, events []E) []Envelope[E] {
+	now := time.Now()
+	out := make([]Envelope[E], len(events))
+	for i, ev := range events {
+		out[i] = Envelope[E]{ID: id, Version: version + i + 1, At: now, Event: ev}
+	}
+	return out
+}
+
+// MemoryStore keeps events in memory. It is safe for concurrent use.
+type MemoryStore[E any] struct {
+	mu      sync.RWMutex
+	streams map[string][]Envelope[E]
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore[E any]() *MemoryStore[E] {
+	return &MemoryStore[E]{streams: make(map[string][]Envelope[E])}
+}
+
+func (s *MemoryStore[E]) Load(id string, after int) ([]Envelope[E], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stream := s.streams[id]
+	if after >= len(stream) {
+		return nil, nil
+	}
+	return append([]Envelope[E](nil), stream[max(after, 0):]...), nil
+}
+
+func (s *MemoryStore[E]) Append(id string, expected int, events []E) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream := s.streams[id]
+	if len(stream) != expected {
+		return len(stream), &ConflictError{ID: id, Expected: expected, Actual: len(stream)}
+	}
+	s.streams[id] = append(stream, envelopes(id, expected, events)...)
+	return expected + len(events), nil
+}
+
+// FileStore keeps the events of each aggregate as JSON lines in a file under its directory.
+// It is safe for concurrent use, provided a single process writes to the directory.
+type FileStore[E any] struct {
+	dir      string
+	mu       sync.Mutex
+	versions map[string]int
+}
+
+// NewFileStore returns a FileStore keeping its files under dir, created on first use.
+func NewFileStore[E any](dir string) *FileStore[E] {
+	return &FileStore[E]{dir: dir, versions: make(map[string]int)}
+}
+
+func (s *FileStore[E]) path(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+".jsonl")
+}
+
+func (s *FileStore[E]) Load(id string, after int) ([]Envelope[E], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read(id)
+	if err != nil || after >= len(all) {
+		return nil, err
+	}
+	return all[max(after, 0):], nil
+}
+
+func (s *FileStore[E]) Append(id string, expected int, events []E) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	version, ok := s.versions[id]
+	if !ok {
+		all, err := s.read(id)
+		if err != nil {
+			return 0, err
+		}
+		version = len(all)
+	}
+	if version != expected {
+		return version, &ConflictError{ID: id, Expected: expected, Actual: version}
+	}
+
+	// Encode the whole batch first, so that a failing event leaves the file untouched.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, env := range envelopes(id, expected, events) {
+		if err := enc.Encode(env); err != nil {
+			return version, err
+		}
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return version, err
+	}
+	f, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return version, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return version, err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		// Drop what was written of the batch; read also discards a torn last line left by a crash.
+		err = errors.Join(err, f.Truncate(info.Size()), f.Close())
+		delete(s.versions, id)
+		return version, err
+	}
+	if err := errors.Join(f.Sync(), f.Close()); err != nil {
+		delete(s.versions, id)
+		return version, err
+	}
+	s.versions[id] = expected + len(events)
+	return expected + len(events), nil
+}
+
+// read returns every event of the aggregate id and caches its version. The lock must be held.
+// A last line without a newline is the remainder of an interrupted Append: it is truncated from the file.
+func (s *FileStore[E]) read(id string) ([]Envelope[E], error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		s.versions[id] = 0
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := os.Truncate(s.path(id), int64(end)); err != nil {
+			return nil, err
+		}
+		data = data[:end]
+	}
+
+	var all []Envelope[E]
+	for line := range bytes.Lines(data) {
+		var env Envelope[E]
+		if err := json.Unmarshal(line, &env); err != nil {
+			return nil, fmt.Errorf("aggregate %q: event %d: %w", id, len(all)+1, err)
+		}
+		all = append(all, env)
+	}
+	s.versions[id] = len(all)
+	return all, nil
+}
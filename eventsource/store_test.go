@@ -0,0 +1,98 @@
+package eventsource
+
+import (
+	"errors"
+	"math"
+	"os"
+	"testing"
+)
+
+func testStore(t *testing.T, s Store[Event]) {
+	t.Helper()
+	v, err := s.Append("a/1", 0, []Event{{Type: "deposited", Amount: 1}, {Type: "deposited", Amount: 2}})
+	if err != nil || v != 2 {
+		t.Fatalf("expected version 2, got %d, %v", v, err)
+	}
+	if _, err := s.Append("a/1", 1, []Event{{Type: "withdrawn"}}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if v, err := s.Append("a/1", 2, []Event{{Type: "withdrawn", Amount: 3}}); err != nil || v != 3 {
+		t.Fatalf("expected version 3, got %d, %v", v, err)
+	}
+
+	envs, err := s.Load("a/1", 1)
+	if err != nil || len(envs) != 2 || envs[0].Version != 2 || envs[1].Event.Amount != 3 || envs[1].ID != "a/1" {
+		t.Fatalf("unexpected events %+v, %v", envs, err)
+	}
+	if envs, err := s.Load("other", 0); err != nil || len(envs) != 0 {
+		t.Fatalf("expected no events, got %+v, %v", envs, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore[Event]())
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	testStore(t, NewFileStore[Event](dir))
+
+	// A new store over the same directory sees the stored events and versions.
+	s := NewFileStore[Event](dir)
+	if _, err := s.Append("a/1", 2, []Event{{Type: "withdrawn"}}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if envs, err := s.Load("a/1", 0); err != nil || len(envs) != 3 {
+		t.Fatalf("expected 3 events, got %d, %v", len(envs), err)
+	}
+}
+
+func TestFileStore_FailedBatchWritesNothing(t *testing.T) {
+	s := NewFileStore[float64](t.TempDir())
+	if _, err := s.Append("a", 0, []float64{1, math.NaN()}); err == nil {
+		t.Fatal("expected NaN to fail encoding")
+	}
+	if v, err := s.Append("a", 0, []float64{1, 2}); err != nil || v != 2 {
+		t.Fatalf("expected the retry to succeed at version 0, got %d, %v", v, err)
+	}
+}
+
+func TestFileStore_TornLastLine(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore[Event](dir)
+	if _, err := s.Append("a", 0, []Event{{Type: "deposited", Amount: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(s.path("a"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"a","version":2,"ev`)
+	f.Close()
+
+	// A restarted store drops the partial event and keeps appending after the last complete one.
+	s = NewFileStore[Event](dir)
+	if envs, err := s.Load("a", 0); err != nil || len(envs) != 1 {
+		t.Fatalf("expected 1 event, got %+v, %v", envs, err)
+	}
+	if v, err := s.Append("a", 1, []Event{{Type: "deposited", Amount: 2}}); err != nil || v != 2 {
+		t.Fatalf("expected version 2, got %d, %v", v, err)
+	}
+	if envs, err := NewFileStore[Event](dir).Load("a", 0); err != nil || len(envs) != 2 || envs[1].Event.Amount != 2 {
+		t.Fatalf("expected 2 events, got %+v, %v", envs, err)
+	}
+}
+
+func TestFileSnapshotStore(t *testing.T) {
+	s := NewFileSnapshotStore[Account](t.TempDir())
+	if _, ok, err := s.LoadSnapshot("a"); ok || err != nil {
+		t.Fatalf("expected no snapshot, got %v, %v", ok, err)
+	}
+	if err := s.SaveSnapshot("a", Snapshot[Account]{Version: 5, State: Account{Balance: 7}}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	snap, ok, err := s.LoadSnapshot("a")
+	if !ok || err != nil || snap.Version != 5 || snap.State.Balance != 7 {
+		t.Fatalf("unexpected snapshot %+v, %v, %v", snap, ok, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/KeibiSoft/go-fp/fpslices"
 	immutable "github.com/KeibiSoft/go-fp/immutable" // replace with your module path
 )
 
@@ -36,14 +37,17 @@ func UnmarshalChain[T any](data []byte) immutable.Chain[T] {
 }
 
 func parseUsers(resp *http.Response) immutable.Chain[[]User] {
-	// This can error, but the error can be lifted into our monadic chain.
-	defer resp.Body.Close()
-	bodyChain := immutable.Wrap(resp.Body)
-	// Bind to ReadAllChain (lifted io.ReadAll)
-	dataChain := immutable.Bind(bodyChain, ReadAllChain)
-	// Bind to UnmarshalChain (lifted json.Unmarshal for []User)
-	usersChain := immutable.Bind(dataChain, UnmarshalChain[[]User])
-	return usersChain
+	// Using closes the body once the chain is done, even if reading or decoding fails.
+	return immutable.Using(func() (io.ReadCloser, error) {
+		return resp.Body, nil
+	}, func(body io.ReadCloser) immutable.Chain[[]User] {
+		// This can error, but the error can be lifted into our monadic chain.
+		bodyChain := immutable.Wrap(body)
+		// Bind to ReadAllChain (lifted io.ReadAll)
+		dataChain := immutable.Bind(bodyChain, ReadAllChain)
+		// Bind to UnmarshalChain (lifted json.Unmarshal for []User)
+		return immutable.Bind(dataChain, UnmarshalChain[[]User])
+	})
 }
 
 func GetChain(url string) immutable.Chain[*http.Response] {
@@ -67,19 +71,16 @@ func main() {
 
 	// Filter users older than 25, map to their names, print
 	usersChain.
-		Then(func(users []User) ([]User, error) {
-			var filtered []User
-			for _, u := range users {
-				if u.Age > 25 {
-					filtered = append(filtered, u)
-				}
-			}
-			return filtered, nil
+		Map(func(users []User) []User {
+			return fpslices.Filter(users, func(u User) bool { return u.Age > 25 })
 		}).
 		Map(func(users []User) []User {
 			fmt.Println("Users older than 25:")
-			for _, u := range users {
-				fmt.Printf("  ID:%d Name:%s Age:%d\n", u.ID, u.Name, u.Age)
+			lines := fpslices.Map(users, func(u User) string {
+				return fmt.Sprintf("  ID:%d Name:%s Age:%d", u.ID, u.Name, u.Age)
+			})
+			for _, line := range lines {
+				fmt.Println(line)
 			}
 			return users
 		}).
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"sync"
+	"slices"
 
+	"github.com/KeibiSoft/go-fp/eventsource"
+	immutable "github.com/KeibiSoft/go-fp/immutable"
 	mutable "github.com/KeibiSoft/go-fp/mutable"
 )
 
@@ -24,39 +29,101 @@ type User struct {
 	Age  int    `json:"age"`
 }
 
+// UserEvent is an event of the user directory.
+type UserEvent struct {
+	Type string `json:"type"` // "added" or "removed"
+	User User   `json:"user"`
+}
+
+// Directory is the user directory aggregate, rebuilt from its events.
+type Directory struct {
+	Users  []User
+	NextID int
+}
+
+func applyUserEvent(d *Directory, ev UserEvent) (*Directory, error) {
+	switch ev.Type {
+	case "added":
+		d.Users = append(d.Users, ev.User)
+		d.NextID = max(d.NextID, ev.User.ID)
+	case "removed":
+		d.Users = slices.DeleteFunc(d.Users, func(u User) bool { return u.ID == ev.User.ID })
+	default:
+		return d, fmt.Errorf("unknown user event %q", ev.Type)
+	}
+	return d, nil
+}
+
+const directoryID = "users"
+
+var errUserNotFound = errors.New("user not found")
+
+// UserStore keeps the users as an event-sourced directory.
 type UserStore struct {
-	mu    sync.RWMutex
-	users []User
+	repo *eventsource.Repository[Directory, UserEvent]
 }
 
-func NewUserStore() *UserStore {
-	return &UserStore{
-		users: []User{
-			{ID: 1, Name: "Alice", Age: 30},
-			{ID: 2, Name: "Bob", Age: 22},
-			{ID: 3, Name: "Carol", Age: 27},
-		},
+func NewUserStore() (*UserStore, error) {
+	s := &UserStore{
+		repo: eventsource.NewRepository(
+			eventsource.NewMemoryStore[UserEvent](),
+			applyUserEvent,
+			logErrorHandler,
+			eventsource.WithSnapshots(eventsource.NewMemorySnapshotStore[Directory](), 100),
+		),
+	}
+	for _, u := range []User{{Name: "Alice", Age: 30}, {Name: "Bob", Age: 22}, {Name: "Carol", Age: 27}} {
+		if err := s.Add(&u); err != nil {
+			return nil, fmt.Errorf("seeding user %q: %w", u.Name, err)
+		}
 	}
+	return s, nil
 }
 
-func (s *UserStore) GetAll() []User {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return append([]User(nil), s.users...) // safe copy
+func (s *UserStore) GetAll() ([]User, error) {
+	d, err := s.repo.Load(directoryID).State().Result()
+	if err != nil {
+		return nil, err
+	}
+	return append([]User(nil), d.Users...), nil // safe copy
 }
 
-func (s *UserStore) Add(u *User) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	u.ID = len(s.users) + 1
-	s.users = append(s.users, *u)
+// maxAttempts bounds how often handle runs a command that keeps conflicting with other requests.
+const maxAttempts = 5
+
+// handle runs cmd on the directory, retrying it if another request saved in between.
+func (s *UserStore) handle(cmd eventsource.Command[Directory, UserEvent]) error {
+	var err error
+	for range maxAttempts {
+		_, err = s.repo.Handle(directoryID, cmd).Result()
+		if !errors.Is(err, eventsource.ErrConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, err)
+}
+
+func (s *UserStore) Add(u *User) error {
+	return s.handle(func(d *Directory) immutable.Chain[[]UserEvent] {
+		u.ID = d.NextID + 1
+		return immutable.Wrap([]UserEvent{{Type: "added", User: *u}})
+	})
+}
+
+func (s *UserStore) Remove(id int) error {
+	return s.handle(func(d *Directory) immutable.Chain[[]UserEvent] {
+		if !slices.ContainsFunc(d.Users, func(u User) bool { return u.ID == id }) {
+			return immutable.Wrap[[]UserEvent](nil).WithError(fmt.Errorf("%w: %d", errUserNotFound, id))
+		}
+		return immutable.Wrap([]UserEvent{{Type: "removed", User: User{ID: id}}})
+	})
 }
 
 // Lift DecodeJSON to Wrapper
 func DecodeJSONWrapper[T any](r io.Reader) *mutable.Wrapper[T] {
 	var val T
 	err := json.NewDecoder(r).Decode(&val)
-	w := mutable.Lift(&val, nil)
+	w := mutable.Lift(&val, nil, mutable.WithNilGuard())
 	if err != nil {
 		return w.WithError(err)
 	}
@@ -77,7 +144,11 @@ func EncodeJSONWrapper(wr http.ResponseWriter, v any) *mutable.Wrapper[NilStruct
 }
 
 func (s *UserStore) handleGetUsers(w http.ResponseWriter, r *http.Request) {
-	users := s.GetAll()
+	users, err := s.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	c1 := mutable.New(&users, logErrorHandler).
 		FlatMap(func(u *[]User) mutable.Wrapper[[]User] {
 			return *mutable.Lift(u, logErrorHandler)
@@ -97,14 +168,26 @@ func (s *UserStore) handleGetUsers(w http.ResponseWriter, r *http.Request) {
 
 func (s *UserStore) handleAddUser(w http.ResponseWriter, r *http.Request) {
 	DecodeJSONWrapper[User](r.Body).
-		Then(func(u *User) (*User, error) {
+		ThenCompensate(func(u *User) (*User, error) {
 			// Add user safely (mutate input user pointer)
-			s.Add(u)
-			return u, nil
+			return u, s.Add(u)
+		}, func(u *User) error {
+			// Remove the user again if a later step fails
+			return s.Remove(u.ID)
 		}).
-		Then(func(_ *User) (*User, error) {
+		Then(func(u *User) (*User, error) {
+			// Encode before writing the header, so that a failure can still be reported
+			var buf bytes.Buffer
+			if err := json.NewEncoder(&buf).Encode(u); err != nil {
+				return u, err
+			}
+			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusCreated)
-			return nil, nil
+			// The user is stored and the header sent, so a write failure is only logged
+			if _, err := buf.WriteTo(w); err != nil {
+				log.Println("writing response:", err)
+			}
+			return u, nil
 		}).
 		Match(nil, func(err error) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -112,7 +195,10 @@ func (s *UserStore) handleAddUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func main() {
-	store := NewUserStore()
+	store, err := NewUserStore()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	http.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -0,0 +1,189 @@
+// Package fpslices provides functional helpers over slices.
+// Fallible helpers return an immutable.Chain, so they compose directly with Then and Bind.
+package fpslices
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+var (
+	// ErrNotFound is stored in the chain returned by Find when no element matches.
+	ErrNotFound = errors.New("fpslices: element not found")
+	// ErrInvalidSize is stored in the chain returned by Chunk for a size lower than one.
+	ErrInvalidSize = errors.New("fpslices: invalid chunk size")
+)
+
+// Map returns the result of f applied to every element of xs.
+// If f is nil, it returns nil.
+func Map[T any, U any](xs []T, f func(T) U) []U {
+	if f == nil {
+		return nil
+	}
+	out := make([]U, 0, len(xs))
+	for _, x := range xs {
+		out = append(out, f(x))
+	}
+	return out
+}
+
+// Filter returns the elements of xs for which pred is true, in order.
+// If pred is nil, it returns xs unchanged.
+func Filter[T any](xs []T, pred func(T) bool) []T {
+	if pred == nil {
+		return xs
+	}
+	var out []T
+	for _, x := range xs {
+		if pred(x) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// FilterMap applies f to every element of xs and keeps the results for which f reports true.
+// If f is nil, it returns nil.
+func FilterMap[T any, U any](xs []T, f func(T) (U, bool)) []U {
+	if f == nil {
+		return nil
+	}
+	var out []U
+	for _, x := range xs {
+		if u, ok := f(x); ok {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// FlatMap applies f to every element of xs and concatenates the results.
+// If f is nil, it returns nil.
+func FlatMap[T any, U any](xs []T, f func(T) []U) []U {
+	if f == nil {
+		return nil
+	}
+	var out []U
+	for _, x := range xs {
+		out = append(out, f(x)...)
+	}
+	return out
+}
+
+// Reduce folds xs from the left, starting with init.
+// If f is nil, it returns init.
+func Reduce[T any, R any](xs []T, init R, f func(R, T) R) R {
+	if f == nil {
+		return init
+	}
+	acc := init
+	for _, x := range xs {
+		acc = f(acc, x)
+	}
+	return acc
+}
+
+// GroupBy groups the elements of xs by the key returned by key, keeping their order within each group.
+// If key is nil, it returns nil.
+func GroupBy[T any, K comparable](xs []T, key func(T) K) map[K][]T {
+	if key == nil {
+		return nil
+	}
+	out := make(map[K][]T)
+	for _, x := range xs {
+		k := key(x)
+		out[k] = append(out[k], x)
+	}
+	return out
+}
+
+// Partition splits xs into the elements for which pred is true and those for which it is false.
+// If pred is nil, every element is returned in the second slice.
+func Partition[T any](xs []T, pred func(T) bool) (matched, rest []T) {
+	for _, x := range xs {
+		if pred != nil && pred(x) {
+			matched = append(matched, x)
+		} else {
+			rest = append(rest, x)
+		}
+	}
+	return matched, rest
+}
+
+// Chunk splits xs into consecutive slices of size elements; the last one may be shorter.
+// The chunks share the backing array of xs.
+// A size lower than one stores ErrInvalidSize in the returned chain.
+func Chunk[T any](xs []T, size int) immutable.Chain[[][]T] {
+	if size < 1 {
+		return immutable.Wrap[[][]T](nil).WithError(fmt.Errorf("%w: %d", ErrInvalidSize, size))
+	}
+	var out [][]T
+	for chunk := range slices.Chunk(xs, size) {
+		out = append(out, chunk)
+	}
+	return immutable.Wrap(out)
+}
+
+// Distinct returns the elements of xs without duplicates, keeping the first occurrence.
+func Distinct[T comparable](xs []T) []T {
+	seen := make(map[T]struct{}, len(xs))
+	var out []T
+	for _, x := range xs {
+		if _, ok := seen[x]; ok {
+			continue
+		}
+		seen[x] = struct{}{}
+		out = append(out, x)
+	}
+	return out
+}
+
+// SortBy returns a copy of xs stably sorted by the key returned by key.
+// If key is nil, it returns an unsorted copy.
+func SortBy[T any, K cmp.Ordered](xs []T, key func(T) K) []T {
+	out := slices.Clone(xs)
+	if key == nil {
+		return out
+	}
+	slices.SortStableFunc(out, func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	})
+	return out
+}
+
+// Find returns a chain holding the first element of xs for which pred is true.
+// If there is none, the chain holds ErrNotFound; a nil pred stores immutable.ErrNilFunc.
+func Find[T any](xs []T, pred func(T) bool) immutable.Chain[T] {
+	var zero T
+	if pred == nil {
+		return immutable.Wrap(zero).WithError(immutable.ErrNilFunc)
+	}
+	for _, x := range xs {
+		if pred(x) {
+			return immutable.Wrap(x)
+		}
+	}
+	return immutable.Wrap(zero).WithError(ErrNotFound)
+}
+
+// TryMap applies the fallible f to every element of xs, stopping at the first error.
+// The error is stored in the returned chain along with the index of the failing element.
+// A nil f stores immutable.ErrNilFunc.
+func TryMap[T any, U any](xs []T, f func(T) (U, error)) immutable.Chain[[]U] {
+	if f == nil {
+		return immutable.Wrap[[]U](nil).WithError(immutable.ErrNilFunc)
+	}
+	out := make([]U, 0, len(xs))
+	for i, x := range xs {
+		u, err := f(x)
+		if err != nil {
+			return immutable.Wrap[[]U](nil).WithError(fmt.Errorf("element %d: %w", i, err))
+		}
+		out = append(out, u)
+	}
+	return immutable.Wrap(out)
+}
@@ -0,0 +1,118 @@
+package fpslices
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+type User struct {
+	Name string
+	Age  int
+}
+
+var users = []User{
+	{Name: "Alice", Age: 30},
+	{Name: "Bob", Age: 22},
+	{Name: "Carol", Age: 27},
+	{Name: "Dave", Age: 22},
+}
+
+func TestMapFilter(t *testing.T) {
+	names := Map(Filter(users, func(u User) bool { return u.Age > 25 }), func(u User) string { return u.Name })
+	if !slices.Equal(names, []string{"Alice", "Carol"}) {
+		t.Fatalf("expected [Alice Carol], got %v", names)
+	}
+	if Map[User, string](users, nil) != nil {
+		t.Fatal("expected nil result with nil function")
+	}
+}
+
+func TestFilterMapFlatMap(t *testing.T) {
+	nums := FilterMap([]string{"1", "x", "3"}, func(s string) (int, bool) {
+		n, err := strconv.Atoi(s)
+		return n, err == nil
+	})
+	if !slices.Equal(nums, []int{1, 3}) {
+		t.Fatalf("expected [1 3], got %v", nums)
+	}
+
+	flat := FlatMap([]int{1, 2}, func(n int) []int { return []int{n, n * 10} })
+	if !slices.Equal(flat, []int{1, 10, 2, 20}) {
+		t.Fatalf("expected [1 10 2 20], got %v", flat)
+	}
+}
+
+func TestReduceGroupByPartition(t *testing.T) {
+	total := Reduce(users, 0, func(acc int, u User) int { return acc + u.Age })
+	if total != 101 {
+		t.Fatalf("expected total age 101, got %d", total)
+	}
+
+	byAge := GroupBy(users, func(u User) int { return u.Age })
+	if len(byAge[22]) != 2 || byAge[22][1].Name != "Dave" {
+		t.Fatalf("unexpected groups %v", byAge)
+	}
+
+	old, young := Partition(users, func(u User) bool { return u.Age > 25 })
+	if len(old) != 2 || len(young) != 2 {
+		t.Fatalf("expected 2/2 partition, got %v / %v", old, young)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	chunks, err := Chunk([]int{1, 2, 3, 4, 5}, 2).Result()
+	if err != nil || len(chunks) != 3 || !slices.Equal(chunks[2], []int{5}) {
+		t.Fatalf("unexpected chunks %v, %v", chunks, err)
+	}
+
+	if _, err := Chunk([]int{1}, 0).Result(); !errors.Is(err, ErrInvalidSize) {
+		t.Fatalf("expected ErrInvalidSize, got %v", err)
+	}
+}
+
+func TestDistinctSortBy(t *testing.T) {
+	if got := Distinct([]int{3, 1, 3, 2, 1}); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Fatalf("expected [3 1 2], got %v", got)
+	}
+
+	sorted := SortBy(users, func(u User) int { return u.Age })
+	names := Map(sorted, func(u User) string { return u.Name })
+	if !slices.Equal(names, []string{"Bob", "Dave", "Carol", "Alice"}) {
+		t.Fatalf("expected stable sort by age, got %v", names)
+	}
+	if users[0].Name != "Alice" {
+		t.Fatal("expected SortBy not to modify its input")
+	}
+}
+
+func TestFind(t *testing.T) {
+	u, err := Find(users, func(u User) bool { return u.Name == "Carol" }).Result()
+	if err != nil || u.Age != 27 {
+		t.Fatalf("expected Carol, got %v, %v", u, err)
+	}
+
+	if _, err := Find(users, func(u User) bool { return u.Age > 100 }).Result(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTryMap_ComposesWithBind(t *testing.T) {
+	parse := func(xs []string) immutable.Chain[[]int] {
+		return TryMap(xs, strconv.Atoi)
+	}
+
+	nums, err := immutable.Bind(immutable.Wrap([]string{"1", "2"}), parse).Result()
+	if err != nil || !slices.Equal(nums, []int{1, 2}) {
+		t.Fatalf("expected [1 2], got %v, %v", nums, err)
+	}
+
+	_, err = immutable.Bind(immutable.Wrap([]string{"1", "x"}), parse).Result()
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatalf("expected wrapped *strconv.NumError, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+// Package fsm builds finite state machines whose transitions are chain steps.
+//
+// A Machine drives an immutable.Chain[S] with transitions of type func(S, E) immutable.Chain[S];
+// a PtrMachine drives a mutable.Wrapper[S] with transitions of type func(*S, E) (*S, error),
+// so failures go through the wrapper error handler.
+// States are identified by the name returned by the key function given to New or NewPtr,
+// which lets S be a rich value such as an order, whose status is its state.
+// Transitions may have a guard, and entry and exit hooks may be attached to states.
+// Machines are configured once and may then be fired concurrently.
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+var (
+	// ErrInvalidTransition is reported when no transition is defined for the event in the current state.
+	ErrInvalidTransition = errors.New("invalid transition")
+	// ErrGuardRejected is reported when the guard of a transition does not hold.
+	ErrGuardRejected = errors.New("guard rejected transition")
+	// ErrTargetMismatch is reported when a transition produces a state other than its declared target.
+	ErrTargetMismatch = errors.New("transition produced unexpected state")
+	// ErrDuplicateTransition is returned when a transition is added twice for the same state and event.
+	ErrDuplicateTransition = errors.New("duplicate transition")
+)
+
+// TransitionError is the error of a failed transition.
+// Err is one of the sentinel errors of the package, or the error of a transition function or hook.
+type TransitionError struct {
+	From  string
+	To    string
+	Event any
+	Err   error
+}
+
+func (e *TransitionError) Error() string {
+	if e.To == "" {
+		return fmt.Sprintf("fsm: %q on %v: %v", e.From, e.Event, e.Err)
+	}
+	return fmt.Sprintf("fsm: %q -[%v]-> %q: %v", e.From, e.Event, e.To, e.Err)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// Record is an entry of the transition history of an instance.
+// To is empty and Err set when the transition failed.
+type Record[E any] struct {
+	From  string
+	To    string
+	Event E
+	Err   error
+}
+
+// history is the transition history of an instance.
+type history[E any] struct {
+	mu      sync.Mutex
+	records []Record[E]
+}
+
+// record adds r to h, which may be nil for machines fired without an instance.
+func (h *history[E]) record(r Record[E]) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+}
+
+func (h *history[E]) get() []Record[E] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Record[E](nil), h.records...)
+}
+
+type edgeKey[E comparable] struct {
+	from string
+	ev   E
+}
+
+// edge is a transition, with V the state as seen by guards and hooks, and F the transition function type.
+type edge[V any, E comparable, F any] struct {
+	from, to string
+	ev       E
+	guard    func(V, E) bool
+	do       F
+}
+
+// graph holds the definition shared by Machine and PtrMachine.
+type graph[V any, E comparable, F any] struct {
+	key   func(V) string
+	edges map[edgeKey[E]]*edge[V, E, F]
+	order []*edge[V, E, F]
+	enter map[string][]func(V) error
+	exit  map[string][]func(V) error
+}
+
+func newGraph[V any, E comparable, F any](key func(V) string) *graph[V, E, F] {
+	if key == nil {
+		key = func(v V) string { return fmt.Sprint(v) }
+	}
+	return &graph[V, E, F]{
+		key:   key,
+		edges: make(map[edgeKey[E]]*edge[V, E, F]),
+		enter: make(map[string][]func(V) error),
+		exit:  make(map[string][]func(V) error),
+	}
+}
+
+func (g *graph[V, E, F]) add(e *edge[V, E, F], nilDo bool) error {
+	if nilDo {
+		return &TransitionError{From: e.from, To: e.to, Event: e.ev, Err: immutable.ErrNilFunc}
+	}
+	k := edgeKey[E]{from: e.from, ev: e.ev}
+	if _, ok := g.edges[k]; ok {
+		return &TransitionError{From: e.from, To: e.to, Event: e.ev, Err: ErrDuplicateTransition}
+	}
+	g.edges[k] = e
+	g.order = append(g.order, e)
+	return nil
+}
+
+// find returns the transition for ev in the state v and checks its guard. It has no side effects.
+func (g *graph[V, E, F]) find(v V, ev E) (*edge[V, E, F], error) {
+	from := g.key(v)
+	e, ok := g.edges[edgeKey[E]{from: from, ev: ev}]
+	if !ok {
+		return nil, &TransitionError{From: from, Event: ev, Err: ErrInvalidTransition}
+	}
+	if e.guard != nil && !e.guard(v, ev) {
+		return nil, e.fail(ErrGuardRejected)
+	}
+	return e, nil
+}
+
+// leave runs the exit hooks of the source state of e.
+func (g *graph[V, E, F]) leave(e *edge[V, E, F], v V) error {
+	for _, hook := range g.exit[e.from] {
+		if err := hook(v); err != nil {
+			return e.fail(err)
+		}
+	}
+	return nil
+}
+
+// arrive checks that next is the target of e and runs its entry hooks.
+func (g *graph[V, E, F]) arrive(e *edge[V, E, F], next V) error {
+	if got := g.key(next); got != e.to {
+		return e.fail(fmt.Errorf("%w: %q", ErrTargetMismatch, got))
+	}
+	for _, hook := range g.enter[e.to] {
+		if err := hook(next); err != nil {
+			return e.fail(err)
+		}
+	}
+	return nil
+}
+
+func (e *edge[V, E, F]) fail(err error) error {
+	if terr, ok := err.(*TransitionError); ok {
+		return terr
+	}
+	return &TransitionError{From: e.from, To: e.to, Event: e.ev, Err: err}
+}
+
+// dot renders the transitions in the Graphviz DOT language, in the order they were added.
+func (g *graph[V, E, F]) dot(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", name)
+	for _, e := range g.order {
+		label := fmt.Sprint(e.ev)
+		if e.guard != nil {
+			label += " [guarded]"
+		}
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q];\n", e.from, e.to, label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
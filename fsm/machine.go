@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"sync"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+// Transition moves a state from From to To on Event.
+// Do computes the new state, which must have the key To; Guard, if set, must hold for the transition to happen.
+type Transition[S any, E comparable] struct {
+	From  string
+	To    string
+	Event E
+	Guard func(S, E) bool
+	Do    func(S, E) immutable.Chain[S]
+}
+
+// Machine is a state machine over immutable.Chain[S].
+type Machine[S any, E comparable] struct {
+	g *graph[S, E, func(S, E) immutable.Chain[S]]
+}
+
+// New returns a Machine naming states with key, or with fmt.Sprint if key is nil.
+func New[S any, E comparable](key func(S) string) *Machine[S, E] {
+	return &Machine[S, E]{g: newGraph[S, E, func(S, E) immutable.Chain[S]](key)}
+}
+
+// Add defines a transition. A nil Do and a second transition for the same state and event are rejected.
+func (m *Machine[S, E]) Add(t Transition[S, E]) error {
+	return m.g.add(&edge[S, E, func(S, E) immutable.Chain[S]]{from: t.From, to: t.To, ev: t.Event, guard: t.Guard, do: t.Do}, t.Do == nil)
+}
+
+// OnEnter adds a hook run after every transition into state. A hook error fails the transition.
+func (m *Machine[S, E]) OnEnter(state string, fn func(S) error) {
+	if fn != nil {
+		m.g.enter[state] = append(m.g.enter[state], fn)
+	}
+}
+
+// OnExit adds a hook run before every transition out of state. A hook error fails the transition.
+func (m *Machine[S, E]) OnExit(state string, fn func(S) error) {
+	if fn != nil {
+		m.g.exit[state] = append(m.g.exit[state], fn)
+	}
+}
+
+// Fire applies the transition for ev to the state held by c, like immutable.Bind.
+// Failures are stored in the returned chain as a *TransitionError; a failed chain is returned unchanged.
+func (m *Machine[S, E]) Fire(c immutable.Chain[S], ev E) immutable.Chain[S] {
+	return immutable.Bind(c, m.Step(ev))
+}
+
+// Step returns the transition for ev as a function usable with immutable.Bind.
+func (m *Machine[S, E]) Step(ev E) func(S) immutable.Chain[S] {
+	return func(s S) immutable.Chain[S] {
+		return m.step(s, ev, nil)
+	}
+}
+
+// step fires ev in the state s, recording the outcome in h if it is not nil.
+func (m *Machine[S, E]) step(s S, ev E, h *history[E]) immutable.Chain[S] {
+	rec := Record[E]{From: m.g.key(s), Event: ev}
+	e, err := m.g.find(s, ev)
+	if err == nil {
+		err = m.g.leave(e, s)
+	}
+	if err != nil {
+		rec.Err = err
+		h.record(rec)
+		return immutable.Wrap(s).WithError(err)
+	}
+
+	next := e.do(s, ev)
+	if err := next.HasError(); err != nil {
+		rec.Err = e.fail(err)
+		h.record(rec)
+		return next.WithError(rec.Err)
+	}
+	return immutable.Bind(next, func(ns S) immutable.Chain[S] {
+		if err := m.g.arrive(e, ns); err != nil {
+			rec.Err = err
+			h.record(rec)
+			return immutable.Wrap(ns).WithError(err)
+		}
+		rec.To = e.to
+		h.record(rec)
+		return immutable.Wrap(ns)
+	})
+}
+
+// DOT renders the transitions of the machine as a Graphviz digraph called name.
+func (m *Machine[S, E]) DOT(name string) string {
+	return m.g.dot(name)
+}
+
+// Start returns an instance of the machine in the state held by c.
+func (m *Machine[S, E]) Start(c immutable.Chain[S]) *Instance[S, E] {
+	return &Instance[S, E]{m: m, c: c, h: &history[E]{}}
+}
+
+// Instance is a machine together with its current state and transition history. It is safe for concurrent use.
+type Instance[S any, E comparable] struct {
+	m  *Machine[S, E]
+	mu sync.Mutex
+	c  immutable.Chain[S]
+	h  *history[E]
+}
+
+// Fire applies the transition for ev, records it in the history and returns the resulting chain.
+// A failed transition leaves the instance in its current state.
+func (i *Instance[S, E]) Fire(ev E) immutable.Chain[S] {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	next := immutable.Bind(i.c, func(s S) immutable.Chain[S] {
+		return i.m.step(s, ev, i.h)
+	})
+	if next.IsSuccess() {
+		i.c = next
+	}
+	return next
+}
+
+// State returns the current state.
+func (i *Instance[S, E]) State() immutable.Chain[S] {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.c
+}
+
+// History returns the transitions fired so far, including the failed one if any.
+func (i *Instance[S, E]) History() []Record[E] {
+	return i.h.get()
+}
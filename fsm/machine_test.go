@@ -0,0 +1,151 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+type Event string
+
+const (
+	Pay    Event = "pay"
+	Ship   Event = "ship"
+	Cancel Event = "cancel"
+)
+
+type Order struct {
+	ID     int
+	Status string
+	Total  int
+}
+
+func moveTo(status string) func(Order, Event) immutable.Chain[Order] {
+	return func(o Order, _ Event) immutable.Chain[Order] {
+		o.Status = status
+		return immutable.Wrap(o)
+	}
+}
+
+func orderMachine(t *testing.T) *Machine[Order, Event] {
+	t.Helper()
+	m := New[Order, Event](func(o Order) string { return o.Status })
+	for _, tr := range []Transition[Order, Event]{
+		{From: "pending", To: "paid", Event: Pay, Do: moveTo("paid"), Guard: func(o Order, _ Event) bool { return o.Total > 0 }},
+		{From: "paid", To: "shipped", Event: Ship, Do: moveTo("shipped")},
+		{From: "pending", To: "cancelled", Event: Cancel, Do: moveTo("cancelled")},
+	} {
+		if err := m.Add(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestMachine_Fire(t *testing.T) {
+	m := orderMachine(t)
+	c := immutable.Wrap(Order{ID: 1, Status: "pending", Total: 10})
+
+	o, err := m.Fire(m.Fire(c, Pay), Ship).Result()
+	if err != nil || o.Status != "shipped" {
+		t.Fatalf("expected shipped, got %+v, %v", o, err)
+	}
+
+	// Step composes with immutable.Bind.
+	if o := immutable.Bind(c, m.Step(Cancel)).Unwrap(); o.Status != "cancelled" {
+		t.Fatalf("expected cancelled, got %+v", o)
+	}
+}
+
+func TestMachine_Errors(t *testing.T) {
+	m := orderMachine(t)
+	var terr *TransitionError
+
+	_, err := m.Fire(immutable.Wrap(Order{Status: "pending", Total: 10}), Ship).Result()
+	if !errors.Is(err, ErrInvalidTransition) || !errors.As(err, &terr) || terr.From != "pending" || terr.Event != Ship {
+		t.Fatalf("expected ErrInvalidTransition from pending, got %v", err)
+	}
+
+	_, err = m.Fire(immutable.Wrap(Order{Status: "pending"}), Pay).Result()
+	if !errors.Is(err, ErrGuardRejected) {
+		t.Fatalf("expected ErrGuardRejected, got %v", err)
+	}
+
+	if err := m.Add(Transition[Order, Event]{From: "paid", To: "shipped", Event: Ship, Do: moveTo("shipped")}); !errors.Is(err, ErrDuplicateTransition) {
+		t.Fatalf("expected ErrDuplicateTransition, got %v", err)
+	}
+	if err := m.Add(Transition[Order, Event]{From: "a", To: "b", Event: Pay}); !errors.Is(err, immutable.ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc, got %v", err)
+	}
+
+	m.Add(Transition[Order, Event]{From: "shipped", To: "returned", Event: Cancel, Do: moveTo("lost")})
+	_, err = m.Fire(immutable.Wrap(Order{Status: "shipped"}), Cancel).Result()
+	if !errors.Is(err, ErrTargetMismatch) {
+		t.Fatalf("expected ErrTargetMismatch, got %v", err)
+	}
+
+	errDown := errors.New("payment service down")
+	m.Add(Transition[Order, Event]{From: "cancelled", To: "pending", Event: Pay, Do: func(o Order, _ Event) immutable.Chain[Order] {
+		return immutable.Wrap(o).WithError(errDown)
+	}})
+	_, err = m.Fire(immutable.Wrap(Order{Status: "cancelled"}), Pay).Result()
+	if !errors.Is(err, errDown) || !errors.As(err, &terr) || terr.To != "pending" {
+		t.Fatalf("expected the transition error to be wrapped, got %v", err)
+	}
+}
+
+func TestMachine_Hooks(t *testing.T) {
+	m := orderMachine(t)
+	var log []string
+	m.OnExit("pending", func(o Order) error {
+		log = append(log, "exit "+o.Status)
+		return nil
+	})
+	m.OnEnter("paid", func(o Order) error {
+		log = append(log, "enter "+o.Status)
+		return nil
+	})
+	errNoStock := errors.New("no stock")
+	m.OnEnter("shipped", func(Order) error { return errNoStock })
+
+	c := m.Fire(immutable.Wrap(Order{Status: "pending", Total: 1}), Pay)
+	if strings.Join(log, ",") != "exit pending,enter paid" {
+		t.Fatalf("unexpected hooks %v", log)
+	}
+	if _, err := m.Fire(c, Ship).Result(); !errors.Is(err, errNoStock) {
+		t.Fatalf("expected the entry hook error, got %v", err)
+	}
+}
+
+func TestInstance_History(t *testing.T) {
+	m := orderMachine(t)
+	inst := m.Start(immutable.Wrap(Order{Status: "pending", Total: 5}))
+
+	inst.Fire(Pay)
+	if inst.Fire(Cancel).IsSuccess() {
+		t.Fatal("expected cancel to be rejected once paid")
+	}
+	inst.Fire(Ship)
+
+	if o := inst.State().Unwrap(); o.Status != "shipped" {
+		t.Fatalf("expected the instance to stay usable after a rejected event, got %+v", o)
+	}
+	h := inst.History()
+	if len(h) != 3 || h[0].To != "paid" || !errors.Is(h[1].Err, ErrInvalidTransition) || h[2].From != "paid" || h[2].To != "shipped" {
+		t.Fatalf("unexpected history %+v", h)
+	}
+}
+
+func TestMachine_DOT(t *testing.T) {
+	want := `digraph "orders" {
+	"pending" -> "paid" [label="pay [guarded]"];
+	"paid" -> "shipped" [label="ship"];
+	"pending" -> "cancelled" [label="cancel"];
+}
+`
+	if got := orderMachine(t).DOT("orders"); got != want {
+		t.Fatalf("unexpected DOT output:\n%s", got)
+	}
+}
@@ -0,0 +1,173 @@
+package fsm
+
+import (
+	"sync"
+
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+// PtrTransition moves a state from From to To on Event, operating on *S.
+// Do may update the state in place or return a new one, which must have the key To.
+// If the machine has a copy strategy, the state is copied once the transition is found valid,
+// before the exit hooks run, and restored in place if the transition fails.
+type PtrTransition[S any, E comparable] struct {
+	From  string
+	To    string
+	Event E
+	Guard func(*S, E) bool
+	Do    func(*S, E) (*S, error)
+}
+
+// PtrMachine is a state machine over mutable.Wrapper[S].
+// Transitions run as Wrapper.Then steps, so their failures go through the wrapper error handler,
+// and the rollback and history options of the wrapper apply.
+type PtrMachine[S any, E comparable] struct {
+	g  *graph[*S, E, func(*S, E) (*S, error)]
+	cp mutable.CopyFunc[S]
+}
+
+// NewPtr returns a PtrMachine naming states with key, or with fmt.Sprint if key is nil.
+// cp is used to snapshot the state of a valid transition so that it can be restored if the transition fails.
+// With a nil cp the machine takes no snapshot, leaving restoration to the WithRollback option of the wrapper.
+func NewPtr[S any, E comparable](key func(S) string, cp mutable.CopyFunc[S]) *PtrMachine[S, E] {
+	var pkey func(*S) string
+	if key != nil {
+		pkey = func(s *S) string { return key(*s) }
+	}
+	return &PtrMachine[S, E]{g: newGraph[*S, E, func(*S, E) (*S, error)](pkey), cp: cp}
+}
+
+// Add defines a transition. A nil Do and a second transition for the same state and event are rejected.
+func (m *PtrMachine[S, E]) Add(t PtrTransition[S, E]) error {
+	return m.g.add(&edge[*S, E, func(*S, E) (*S, error)]{from: t.From, to: t.To, ev: t.Event, guard: t.Guard, do: t.Do}, t.Do == nil)
+}
+
+// OnEnter adds a hook run after every transition into state. A hook error fails the transition.
+func (m *PtrMachine[S, E]) OnEnter(state string, fn func(*S) error) {
+	if fn != nil {
+		m.g.enter[state] = append(m.g.enter[state], fn)
+	}
+}
+
+// OnExit adds a hook run before every transition out of state. A hook error fails the transition.
+func (m *PtrMachine[S, E]) OnExit(state string, fn func(*S) error) {
+	if fn != nil {
+		m.g.exit[state] = append(m.g.exit[state], fn)
+	}
+}
+
+// Fire applies the transition for ev to the state held by w, as a Then step.
+// A nil state fails with mutable.ErrNilValue, other failures with a *TransitionError.
+func (m *PtrMachine[S, E]) Fire(w mutable.Wrapper[S], ev E) mutable.Wrapper[S] {
+	return w.Then(m.Step(ev))
+}
+
+// Step returns the transition for ev as a function usable with Wrapper.Then.
+func (m *PtrMachine[S, E]) Step(ev E) func(*S) (*S, error) {
+	return func(s *S) (*S, error) {
+		return m.step(s, ev, nil)
+	}
+}
+
+func (m *PtrMachine[S, E]) step(s *S, ev E, h *history[E]) (*S, error) {
+	if s == nil {
+		return nil, mutable.ErrNilValue
+	}
+	rec := Record[E]{From: m.g.key(s), Event: ev}
+	next, err := m.transition(s, ev)
+	if err != nil {
+		rec.Err = err
+		h.record(rec)
+		return s, err
+	}
+	rec.To = m.g.key(next)
+	h.record(rec)
+	return next, nil
+}
+
+// transition applies the transition for ev to s. Invalid and guarded events fail before anything is copied;
+// otherwise s is snapshotted with the copy strategy of the machine and restored if a hook or Do fails or panics.
+func (m *PtrMachine[S, E]) transition(s *S, ev E) (next *S, err error) {
+	e, err := m.g.find(s, ev)
+	if err != nil {
+		return s, err
+	}
+	if m.cp != nil {
+		snap, cerr := m.cp(s)
+		if cerr != nil {
+			return s, e.fail(&mutable.SnapshotError{Err: cerr})
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				*s = *snap
+				panic(r)
+			}
+			if err != nil {
+				*s = *snap
+			}
+		}()
+	}
+	return m.apply(s, e, ev)
+}
+
+func (m *PtrMachine[S, E]) apply(s *S, e *edge[*S, E, func(*S, E) (*S, error)], ev E) (*S, error) {
+	if err := m.g.leave(e, s); err != nil {
+		return s, err
+	}
+	next, err := e.do(s, ev)
+	if err != nil {
+		return s, e.fail(err)
+	}
+	if next == nil {
+		return s, e.fail(mutable.ErrNilValue)
+	}
+	if err := m.g.arrive(e, next); err != nil {
+		return s, err
+	}
+	return next, nil
+}
+
+// DOT renders the transitions of the machine as a Graphviz digraph called name.
+func (m *PtrMachine[S, E]) DOT(name string) string {
+	return m.g.dot(name)
+}
+
+// Start returns an instance of the machine in the state held by w.
+func (m *PtrMachine[S, E]) Start(w mutable.Wrapper[S]) *PtrInstance[S, E] {
+	return &PtrInstance[S, E]{m: m, w: w, h: &history[E]{}}
+}
+
+// PtrInstance is a PtrMachine together with its current state and transition history.
+// It is safe for concurrent use.
+type PtrInstance[S any, E comparable] struct {
+	m  *PtrMachine[S, E]
+	mu sync.Mutex
+	w  mutable.Wrapper[S]
+	h  *history[E]
+}
+
+// Fire applies the transition for ev, records it in the history and returns the resulting wrapper.
+// A failed transition leaves the instance in its current state.
+func (i *PtrInstance[S, E]) Fire(ev E) mutable.Wrapper[S] {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	next := i.w.Then(func(s *S) (*S, error) {
+		return i.m.step(s, ev, i.h)
+	})
+	if next.IsSuccess() {
+		i.w = next
+	}
+	return next
+}
+
+// State returns the current state.
+func (i *PtrInstance[S, E]) State() mutable.Wrapper[S] {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.w
+}
+
+// History returns the transitions fired so far, including failed ones.
+func (i *PtrInstance[S, E]) History() []Record[E] {
+	return i.h.get()
+}
@@ -0,0 +1,152 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+func ptrMoveTo(status string) func(*Order, Event) (*Order, error) {
+	return func(o *Order, _ Event) (*Order, error) {
+		o.Status = status
+		return o, nil
+	}
+}
+
+func ptrOrderMachine(t *testing.T) *PtrMachine[Order, Event] {
+	t.Helper()
+	m := NewPtr[Order, Event](func(o Order) string { return o.Status }, nil)
+	for _, tr := range []PtrTransition[Order, Event]{
+		{From: "pending", To: "paid", Event: Pay, Do: ptrMoveTo("paid"), Guard: func(o *Order, _ Event) bool { return o.Total > 0 }},
+		{From: "paid", To: "shipped", Event: Ship, Do: ptrMoveTo("shipped")},
+	} {
+		if err := m.Add(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestPtrMachine_Fire(t *testing.T) {
+	m := ptrOrderMachine(t)
+	o := &Order{Status: "pending", Total: 3}
+
+	got, err := m.Fire(m.Fire(mutable.New(o, nil), Pay), Ship).Result()
+	if err != nil || got.Status != "shipped" || o.Status != "shipped" {
+		t.Fatalf("expected the order to be shipped in place, got %+v, %v", got, err)
+	}
+
+	_, err = m.Fire(mutable.New(&Order{Status: "pending"}, nil), Pay).Result()
+	if !errors.Is(err, ErrGuardRejected) {
+		t.Fatalf("expected ErrGuardRejected, got %v", err)
+	}
+
+	_, err = m.Fire(mutable.New[Order](nil, nil), Pay).Result()
+	if !errors.Is(err, mutable.ErrNilValue) {
+		t.Fatalf("expected ErrNilValue, got %v", err)
+	}
+}
+
+func TestPtrMachine_ErrHandler(t *testing.T) {
+	m := ptrOrderMachine(t)
+	var handled error
+	w := mutable.New(&Order{Status: "pending", Total: 1}, func(err error) error {
+		handled = err
+		return err
+	})
+
+	if _, err := m.Fire(w, Ship).Result(); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if !errors.Is(handled, ErrInvalidTransition) {
+		t.Fatalf("expected the error handler to see the transition error, got %v", handled)
+	}
+}
+
+// failingPayMachine returns a machine whose Pay transition updates the order and then fails in its entry hook.
+func failingPayMachine(cp mutable.CopyFunc[Order]) (*PtrMachine[Order, Event], error) {
+	m := NewPtr[Order, Event](func(o Order) string { return o.Status }, cp)
+	m.Add(PtrTransition[Order, Event]{From: "pending", To: "paid", Event: Pay, Do: func(o *Order, _ Event) (*Order, error) {
+		o.Status = "paid"
+		o.Total = 99
+		return o, nil
+	}})
+	errAudit := errors.New("audit unavailable")
+	m.OnEnter("paid", func(*Order) error { return errAudit })
+	return m, errAudit
+}
+
+func TestPtrInstance_FailedHookRestoresState(t *testing.T) {
+	m, errAudit := failingPayMachine(mutable.ShallowCopy[Order])
+
+	o := &Order{Status: "pending", Total: 3}
+	inst := m.Start(mutable.New(o, nil))
+	if _, err := inst.Fire(Pay).Result(); !errors.Is(err, errAudit) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+	got, err := inst.State().Result()
+	if err != nil || *got != (Order{Status: "pending", Total: 3}) {
+		t.Fatalf("expected the instance to stay pending with total 3, got %+v, %v", got, err)
+	}
+}
+
+func TestPtrMachine_WrapperRollback(t *testing.T) {
+	m, errAudit := failingPayMachine(nil)
+
+	o := &Order{Status: "pending", Total: 3}
+	w := mutable.New(o, nil, mutable.WithRollback(mutable.ShallowCopy[Order]))
+	if _, err := m.Fire(w, Pay).Result(); !errors.Is(err, errAudit) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+	if *o != (Order{Status: "pending", Total: 3}) {
+		t.Fatalf("expected the wrapper rollback to restore the order, got %+v", *o)
+	}
+}
+
+func TestPtrMachine_SnapshotsValidTransitionsOnly(t *testing.T) {
+	copies := 0
+	m := NewPtr[Order, Event](func(o Order) string { return o.Status }, func(o *Order) (*Order, error) {
+		copies++
+		return mutable.ShallowCopy(o)
+	})
+	m.Add(PtrTransition[Order, Event]{From: "pending", To: "paid", Event: Pay, Do: ptrMoveTo("paid"), Guard: func(o *Order, _ Event) bool { return o.Total > 0 }})
+
+	m.Fire(mutable.New(&Order{Status: "pending"}, nil), Pay)
+	m.Fire(mutable.New(&Order{Status: "pending", Total: 1}, nil), Ship)
+	if copies != 0 {
+		t.Fatalf("expected no snapshot for rejected events, got %d", copies)
+	}
+	if _, err := m.Fire(mutable.New(&Order{Status: "pending", Total: 1}, nil), Pay).Result(); err != nil || copies != 1 {
+		t.Fatalf("expected one snapshot for a valid transition, got %d, %v", copies, err)
+	}
+
+	errCopy := errors.New("copy failed")
+	m.cp = func(*Order) (*Order, error) { return nil, errCopy }
+	o := &Order{Status: "pending", Total: 1}
+	var serr *mutable.SnapshotError
+	if _, err := m.Fire(mutable.New(o, nil), Pay).Result(); !errors.As(err, &serr) || !errors.Is(err, errCopy) {
+		t.Fatalf("expected a *SnapshotError, got %v", err)
+	}
+	if o.Status != "pending" {
+		t.Fatalf("expected the transition not to run without a snapshot, got %+v", o)
+	}
+}
+
+func TestPtrInstance_History(t *testing.T) {
+	m := ptrOrderMachine(t)
+	inst := m.Start(mutable.New(&Order{Status: "pending", Total: 1}, nil))
+
+	inst.Fire(Ship)
+	inst.Fire(Pay)
+	if got := inst.State().Unwrap(); got.Status != "paid" {
+		t.Fatalf("expected paid, got %+v", got)
+	}
+	h := inst.History()
+	if len(h) != 2 || h[0].Err == nil || h[1].To != "paid" {
+		t.Fatalf("unexpected history %+v", h)
+	}
+	if m.DOT("orders") == "" {
+		t.Fatal("expected DOT output")
+	}
+}
@@ -1,17 +1,23 @@
 package chain
 
-import "fmt"
+import (
+	"fmt"
+	"runtime/debug"
+)
 
 // Chain provides a generic chainable wrapper with error handling.
 // It supports chaining functions returning (T, error) in a semi-functional style
 type Chain[T any] struct {
-	val T
-	err error
+	val    T
+	err    error
+	opts   options
+	defers *cleanup
 }
 
 // Wrap creates a new Chain wrapping the given value.
-func Wrap[T any](v T) Chain[T] {
-	return Chain[T]{val: v}
+// Options such as WithPanicSafe apply to every subsequent step.
+func Wrap[T any](v T, opts ...Option) Chain[T] {
+	return Chain[T]{val: v, opts: newOptions(opts)}
 }
 
 func (c Chain[T]) WithError(err error) Chain[T] {
@@ -21,18 +27,26 @@ func (c Chain[T]) WithError(err error) Chain[T] {
 
 // Then calls f if no error yet, else skips.
 // f returns the updated value and optional error.
-// If the function is nil, return old value unchanged.
+// If the function is nil, return old value unchanged, or ErrNilFunc in strict mode.
+// In panic-safe mode a panic in f keeps the old value and stores a *PanicError.
 func (c Chain[T]) Then(f func(T) (T, error)) Chain[T] {
-	if c.err != nil || f == nil {
+	if c.err != nil {
 		return c
 	}
-	newVal, err := f(c.val)
-	return Chain[T]{val: newVal, err: err}
+	if f == nil {
+		return c.nilFunc()
+	}
+	check := checkMutation(c.opts, c.val)
+	newVal, err := protect(c.opts.panicSafe, c.val, func() (T, error) {
+		return f(c.val)
+	})
+	return Chain[T]{val: newVal, err: withMutation(err, check), opts: c.opts, defers: c.defers}
 }
 
 // Result returns the final value and error of the chain.
+// It runs the deferred actions first, joining their errors with the chain error.
 func (c Chain[T]) Result() (T, error) {
-	return c.val, c.err
+	return c.val, c.finish()
 }
 
 // Map applies f to the value if no error, ignoring errors.
@@ -40,15 +54,37 @@ func (c Chain[T]) Map(f func(T) T) Chain[T] {
 	if c.err != nil {
 		return c
 	}
-	return Chain[T]{val: f(c.val)}
+	if f == nil && c.opts.strict {
+		return c.nilFunc()
+	}
+	check := checkMutation(c.opts, c.val)
+	newVal, err := protect(c.opts.panicSafe, c.val, func() (T, error) {
+		return f(c.val), nil
+	})
+	return Chain[T]{val: newVal, err: withMutation(err, check), opts: c.opts, defers: c.defers}
 }
 
+// Filter sets err on the chain if pred does not hold for the value.
+// In strict mode a nil err is reported as ErrPredicateFailed.
 func (c Chain[T]) Filter(pred func(T) bool, err error) Chain[T] {
 	if c.err != nil {
 		return c
 	}
-	if !pred(c.val) {
-		return Chain[T]{c.val, err}
+	if pred == nil && c.opts.strict {
+		return c.nilFunc()
+	}
+	if err == nil && c.opts.strict {
+		err = ErrPredicateFailed
+	}
+	check := checkMutation(c.opts, c.val)
+	ok, perr := protect(c.opts.panicSafe, false, func() (bool, error) {
+		return pred(c.val), nil
+	})
+	if perr = withMutation(perr, check); perr != nil {
+		return Chain[T]{val: c.val, err: perr, opts: c.opts, defers: c.defers}
+	}
+	if !ok {
+		return Chain[T]{val: c.val, err: err, opts: c.opts, defers: c.defers}
 	}
 
 	return c
@@ -57,10 +93,11 @@ func (c Chain[T]) Filter(pred func(T) bool, err error) Chain[T] {
 // Match invokes success with the value if no error,
 // otherwise invokes failure with the error.
 // Nil functions are safely ignored.
+// It runs the deferred actions first, a failing action makes the chain a failure.
 func (c Chain[T]) Match(success func(T), failure func(error)) {
-	if c.err != nil {
+	if err := c.finish(); err != nil {
 		if failure != nil {
-			failure(c.err)
+			failure(err)
 		}
 		return
 	}
@@ -75,7 +112,7 @@ func (c Chain[T]) Match(success func(T), failure func(error)) {
 // it replaces the value, but it does not clear the error.
 func (c Chain[T]) OrElse(defaultVal T) Chain[T] {
 	if c.err != nil {
-		return Chain[T]{val: defaultVal, err: c.err} // preserve error
+		return Chain[T]{val: defaultVal, err: c.err, opts: c.opts, defers: c.defers} // preserve error
 	}
 	return c
 }
@@ -84,31 +121,34 @@ func (c Chain[T]) OrElse(defaultVal T) Chain[T] {
 // If the outer or inner chain has an error, it propagates that error.
 func Flatten[U any](c Chain[Chain[U]]) Chain[U] {
 	if c.err != nil {
-		return Chain[U]{err: c.err}
+		return Chain[U]{err: c.err, opts: c.opts, defers: c.defers}
 	}
 	inner := c.val
 	if inner.err != nil {
-		return Chain[U]{err: inner.err}
+		return Chain[U]{err: inner.err, opts: c.opts.merge(inner.opts), defers: inner.defers.then(c.defers)}
 	}
-	return Chain[U]{val: inner.val}
+	return Chain[U]{val: inner.val, opts: c.opts.merge(inner.opts), defers: inner.defers.then(c.defers)}
 }
 
 // Recover executes fn and recovers from any panic,
-// converting it into an error stored in the chain.
+// converting it into a *PanicError stored in the chain.
 // If the chain already has an error or if fn is nil, it does nothing.
 func (c Chain[T]) Recover(fn func() (T, error)) (result Chain[T]) {
-	if c.err != nil || fn == nil {
+	if c.err != nil {
 		return c
 	}
+	if fn == nil {
+		return c.nilFunc()
+	}
 
 	defer func() {
 		if r := recover(); r != nil {
-			result = Chain[T]{err: fmt.Errorf("panic recovered: %v", r)}
+			result = Chain[T]{err: &PanicError{Value: r, Stack: debug.Stack()}, opts: c.opts, defers: c.defers}
 		}
 	}()
 
 	val, err := fn()
-	result = Chain[T]{val: val, err: err}
+	result = Chain[T]{val: val, err: err, opts: c.opts, defers: c.defers}
 	return
 }
 
@@ -136,7 +176,7 @@ func FilterChains[T any](chains []Chain[T], predicate func(T) bool) []Chain[T] {
 
 // MapReduceChains maps each Chain's value using mapFn, then reduces the results using reduceFn.
 // Returns zero value if chains is empty or if mapFn or reduceFn is nil.
-// Skips Chains with errors in the slice.
+// Skips Chains with errors in the slice; use MapReduceReport to find out which ones.
 func MapReduceChains[T any, R any](chains []Chain[T], mapFn func(T) R, reduceFn func(R, R) R, zero R) R {
 	if chains == nil || mapFn == nil || reduceFn == nil {
 		return zero
@@ -164,24 +204,36 @@ func MapReduceChains[T any, R any](chains []Chain[T], mapFn func(T) R, reduceFn
 // Unwrap returns the contained value if no error occurred,
 // otherwise it panics with the error.
 // Similar to Rust's unwrap().
+// It runs the deferred actions first, a failing action makes it panic as well.
 func (c Chain[T]) Unwrap() T {
-	if c.err != nil {
-		panic(fmt.Sprintf("called Unwrap on error: %v", c.err))
+	if err := c.finish(); err != nil {
+		panic(fmt.Sprintf("called Unwrap on error: %v", err))
 	}
 	return c.val
 }
 
+// nilFunc returns c unchanged, or with ErrNilFunc in strict mode.
+func (c Chain[T]) nilFunc() Chain[T] {
+	if c.opts.strict {
+		return Chain[T]{val: c.val, err: ErrNilFunc, opts: c.opts, defers: c.defers}
+	}
+	return c
+}
+
 // IsSuccess returns true if the chain has no error.
+// It only inspects the chain: deferred actions do not run, and their errors are not reported.
 func (c Chain[T]) IsSuccess() bool {
 	return c.err == nil
 }
 
 // IsFailure returns true if the chain has an error.
+// It only inspects the chain: deferred actions do not run, and their errors are not reported.
 func (c Chain[T]) IsFailure() bool {
 	return c.err != nil
 }
 
 // HasError returns the error if any, otherwise nil.
+// It only inspects the chain: deferred actions do not run, and their errors are not reported.
 func (c Chain[T]) HasError() error {
 	return c.err
 }
@@ -189,39 +241,56 @@ func (c Chain[T]) HasError() error {
 // Bind applies a function that returns a Chain[U] to the current Chain's value if there is no error.
 // It returns a new Chain[U] with the result of applying the function.
 // If the current Chain has an error, Bind propagates it without calling the function.
-// If the function f is nil, Bind returns the original Chain converted to Chain[U] with zero value U.
+// If the function f is nil, Bind returns the original Chain converted to Chain[U] with zero value U,
+// with ErrNilFunc in strict mode.
+// The options of c carry over to the returned chain, merged with those of the chain returned by f.
 func Bind[T any, U any](c Chain[T], f func(T) Chain[U]) Chain[U] {
 	if c.err != nil {
-		return Chain[U]{err: c.err}
+		return Chain[U]{err: c.err, opts: c.opts, defers: c.defers}
 	}
 	if f == nil {
 		// Can't apply nil function; return zero value with no error.
 		var zeroU U
-		return Chain[U]{val: zeroU}
+		return Chain[U]{val: zeroU, err: c.opts.strictErr(ErrNilFunc), opts: c.opts, defers: c.defers}
+	}
+	check := checkMutation(c.opts, c.val)
+	next, err := protect(c.opts.panicSafe, Chain[U]{}, func() (Chain[U], error) {
+		return f(c.val), nil
+	})
+	if err = withMutation(err, check); err != nil {
+		return Chain[U]{err: err, opts: c.opts, defers: c.defers}
 	}
-	return f(c.val)
+	next.opts = c.opts.merge(next.opts)
+	next.defers = next.defers.then(c.defers)
+	return next
 }
 
 // Apply applies a wrapped function (Chain of func(T) U) to the current Chain's value if there are no errors.
 // It returns a new Chain[U] containing the result of applying the function.
 // If either the current Chain or the function Chain has an error, Apply propagates the error and does not call the function.
-// If the function Chain's value is nil, or if the function Chain itself is nil, returns a zero value Chain[U].
+// If the function Chain's value is nil, or if the function Chain itself is nil, returns a zero value Chain[U],
+// with ErrNilFunc in strict mode.
 func Apply[T any, U any](c Chain[T], f Chain[func(T) U]) Chain[U] {
 	if c.err != nil {
-		return Chain[U]{err: c.err}
+		return Chain[U]{err: c.err, opts: c.opts, defers: c.defers}
 	}
 	if f.err != nil {
-		return Chain[U]{err: f.err}
+		return Chain[U]{err: f.err, opts: c.opts, defers: c.defers}
 	}
 	if f.val == nil {
 		var zeroU U
-		return Chain[U]{val: zeroU}
+		return Chain[U]{val: zeroU, err: c.opts.strictErr(ErrNilFunc), opts: c.opts, defers: c.defers}
 	}
-	return Chain[U]{val: f.val(c.val)}
+	check := checkMutation(c.opts, c.val)
+	val, err := protect(c.opts.panicSafe, *new(U), func() (U, error) {
+		return f.val(c.val), nil
+	})
+	return Chain[U]{val: val, err: withMutation(err, check), opts: c.opts, defers: c.defers}
 }
 
-func Lift[T any](v T) Chain[T] {
-	return Wrap(v)
+// Lift wraps a value into a Chain[T], same as Wrap.
+func Lift[T any](v T, opts ...Option) Chain[T] {
+	return Wrap(v, opts...)
 }
 
 // LiftResult lifts a function that returns (T, error) into a Chain[T].
@@ -243,16 +312,20 @@ func LiftResult[T any](fn func() (T, error)) Chain[T] {
 // a Chain[T] into a Chain[U], applying f only if the Chain has no error.
 //
 // If the input chain has an error, it returns a new Chain[U] with the same error.
-// If the function f is nil, it returns a zero value Chain[U] with no error.
+// If the function f is nil, it returns a zero value Chain[U] with no error, or ErrNilFunc in strict mode.
 func LiftM[T any, U any](f func(T) U) func(Chain[T]) Chain[U] {
 	return func(c Chain[T]) Chain[U] {
 		if c.err != nil {
-			return Chain[U]{err: c.err}
+			return Chain[U]{err: c.err, opts: c.opts, defers: c.defers}
 		}
 		if f == nil {
 			var zeroU U
-			return Chain[U]{val: zeroU}
+			return Chain[U]{val: zeroU, err: c.opts.strictErr(ErrNilFunc), opts: c.opts, defers: c.defers}
 		}
-		return Wrap(f(c.val))
+		check := checkMutation(c.opts, c.val)
+		val, err := protect(c.opts.panicSafe, *new(U), func() (U, error) {
+			return f(c.val), nil
+		})
+		return Chain[U]{val: val, err: withMutation(err, check), opts: c.opts, defers: c.defers}
 	}
 }
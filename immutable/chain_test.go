@@ -531,3 +531,30 @@ func TestApply(t *testing.T) {
 		t.Fatal("Apply with nil function val should return zero value")
 	}
 }
+
+func TestRecover_PanicError(t *testing.T) {
+	c := Wrap(MyStruct{Val: 0})
+
+	res := c.Recover(func() (MyStruct, error) {
+		panic(42)
+	})
+	var pe *PanicError
+	if !errors.As(res.err, &pe) {
+		t.Fatalf("expected *PanicError, got %T", res.err)
+	}
+	if v, ok := pe.Value.(int); !ok || v != 42 {
+		t.Fatalf("expected panic value 42, got %v", pe.Value)
+	}
+	if len(pe.Stack) == 0 {
+		t.Fatal("expected stack trace to be captured")
+	}
+
+	// panic value that is an error can be unwrapped
+	errBoom := errors.New("boom")
+	res2 := c.Recover(func() (MyStruct, error) {
+		panic(errBoom)
+	})
+	if !errors.Is(res2.err, errBoom) {
+		t.Fatalf("expected errors.Is to match panic error, got %v", res2.err)
+	}
+}
@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"errors"
+	"sync"
+)
+
+// cleanup is a persistent stack of deferred actions, shared by the chains derived from each other.
+// Each action runs at most once; its error is remembered for later terminal operations.
+type cleanup struct {
+	fn   func() error
+	next *cleanup
+	once sync.Once
+	err  error
+}
+
+// run executes the actions in LIFO order and returns their joined errors.
+func (s *cleanup) run() error {
+	var errs []error
+	for n := s; n != nil; n = n.next {
+		n.once.Do(func() {
+			n.err = n.fn()
+		})
+		if n.err != nil {
+			errs = append(errs, n.err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// then returns a stack that runs s before next.
+func (s *cleanup) then(next *cleanup) *cleanup {
+	if s == nil {
+		return next
+	}
+	if next == nil || s == next {
+		return s
+	}
+	return &cleanup{fn: s.run, next: next}
+}
+
+// Defer registers fn to run when a terminal operation (Result, Match or Unwrap) is called.
+// IsSuccess, IsFailure and HasError are not terminal and do not run it.
+// Deferred actions run in LIFO order, at most once, and their errors are joined with the chain error.
+// If the chain already has an error, fn is not registered, just like a skipped step.
+// A nil fn is skipped, or reported as ErrNilFunc in strict mode.
+func (c Chain[T]) Defer(fn func() error) Chain[T] {
+	if c.err != nil {
+		return c
+	}
+	if fn == nil {
+		return c.nilFunc()
+	}
+	c.defers = &cleanup{fn: fn, next: c.defers}
+	return c
+}
+
+// finish runs the deferred actions and returns the chain error joined with their errors.
+func (c Chain[T]) finish() error {
+	if cerr := c.defers.run(); cerr != nil {
+		return errors.Join(c.err, cerr)
+	}
+	return c.err
+}
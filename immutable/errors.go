@@ -0,0 +1,21 @@
+package chain
+
+import (
+	"errors"
+
+	"github.com/KeibiSoft/go-fp/internal/shared"
+)
+
+// Sentinel errors reported in strict mode, see WithStrict.
+var (
+	// ErrNilFunc is reported when a step is given a nil function.
+	// It is the same value as mutable.ErrNilFunc.
+	ErrNilFunc = shared.ErrNilFunc
+	// ErrPredicateFailed is reported when Filter rejects a value and no error was provided.
+	ErrPredicateFailed = errors.New("predicate failed")
+)
+
+// PanicError is the error stored in a Chain when a panic is recovered.
+// It keeps the original panic value and the stack trace of the panicking goroutine.
+// It is the same type as mutable.PanicError, so errors.As matches panics recovered by either package.
+type PanicError = shared.PanicError
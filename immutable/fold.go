@@ -0,0 +1,119 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+var (
+	// ErrEmptyInput is reported by Reduce when the sequence yields no usable value.
+	ErrEmptyInput = errors.New("empty input")
+	// ErrAllFailed is reported by Fold and Reduce with SkipErrors when every chain of the sequence failed.
+	ErrAllFailed = errors.New("all inputs failed")
+)
+
+// FoldOption configures a Fold or Reduce accumulating values of type R.
+type FoldOption[R any] func(*foldOptions[R])
+
+type foldOptions[R any] struct {
+	skipErrors bool
+	stop       func(R) bool
+}
+
+// SkipErrors makes Fold and Reduce skip failed chains instead of stopping at the first one.
+// If every chain failed, the result holds ErrAllFailed joined with their errors.
+func SkipErrors[R any]() FoldOption[R] {
+	return func(o *foldOptions[R]) {
+		o.skipErrors = true
+	}
+}
+
+// StopWhen stops consuming the sequence as soon as pred holds for the accumulated value.
+func StopWhen[R any](pred func(R) bool) FoldOption[R] {
+	return func(o *foldOptions[R]) {
+		if pred != nil {
+			o.stop = pred
+		}
+	}
+}
+
+// Fold consumes seq, accumulating the values of its chains into init with f.
+// By default it stops at the first failed chain and returns its error as an IndexedError;
+// with SkipErrors failed chains are skipped. With StopWhen it stops as soon as the accumulator is done,
+// without pulling further values from seq.
+// The returned chain carries the merged options and deferred actions of every chain consumed.
+// An empty seq gives init. If f is nil, the result holds ErrNilFunc and seq is not consumed.
+func Fold[T any, R any](seq iter.Seq[Chain[T]], init R, f func(R, T) R, opts ...FoldOption[R]) Chain[R] {
+	out, _ := fold(seq, init, f, false, opts)
+	return out
+}
+
+// Reduce works like Fold, using the first usable value of seq as the initial accumulator.
+// If seq yields no chain at all, the result holds ErrEmptyInput.
+func Reduce[T any](seq iter.Seq[Chain[T]], f func(T, T) T, opts ...FoldOption[T]) Chain[T] {
+	out, used := fold(seq, *new(T), f, true, opts)
+	if out.err == nil && used == 0 {
+		out.err = ErrEmptyInput
+	}
+	return out
+}
+
+// fold implements Fold and Reduce, returning the number of values accumulated.
+// With seeded set, the first value replaces init instead of being passed to f.
+func fold[T any, R any](seq iter.Seq[Chain[T]], init R, f func(R, T) R, seeded bool, opts []FoldOption[R]) (Chain[R], int) {
+	var o foldOptions[R]
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	out := Chain[R]{val: init}
+	if f == nil {
+		out.err = ErrNilFunc
+		return out, 0
+	}
+	if seq == nil {
+		return out, 0
+	}
+
+	var skipped []error
+	used := 0
+	for c := range seq {
+		out.opts = out.opts.merge(c.opts)
+		out.defers = c.defers.then(out.defers)
+
+		if c.err != nil {
+			ierr := IndexedError{Index: used + len(skipped), Err: c.err}
+			if !o.skipErrors {
+				out.err = ierr
+				return out, used
+			}
+			skipped = append(skipped, ierr)
+			continue
+		}
+
+		acc, err := protect(out.opts.panicSafe, out.val, func() (R, error) {
+			if seeded && used == 0 {
+				return any(c.val).(R), nil
+			}
+			return f(out.val, c.val), nil
+		})
+		if err != nil {
+			out.err = err
+			return out, used
+		}
+		out.val = acc
+		used++
+
+		if o.stop != nil && o.stop(out.val) {
+			return out, used
+		}
+	}
+
+	if used == 0 && len(skipped) > 0 {
+		out.err = fmt.Errorf("%w: %w", ErrAllFailed, errors.Join(skipped...))
+	}
+	return out, used
+}
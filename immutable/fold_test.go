@@ -0,0 +1,103 @@
+package chain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func add(a, b int) int { return a + b }
+
+func TestFold(t *testing.T) {
+	seq := slices.Values([]Chain[int]{Wrap(1), Wrap(2), Wrap(3)})
+
+	got, err := Fold(seq, "", func(acc string, v int) string {
+		return acc + string(rune('a'+v))
+	}).Result()
+	if err != nil || got != "bcd" {
+		t.Fatalf("expected bcd, got %q, %v", got, err)
+	}
+
+	if got, err := Fold(slices.Values([]Chain[int]{}), 10, add).Result(); err != nil || got != 10 {
+		t.Fatalf("expected init on empty input, got %d, %v", got, err)
+	}
+}
+
+func TestFold_FailFast(t *testing.T) {
+	errBoom := errors.New("boom")
+	seq := slices.Values([]Chain[int]{Wrap(1), Wrap(0).WithError(errBoom), Wrap(3)})
+
+	_, err := Fold(seq, 0, add).Result()
+	var ierr IndexedError
+	if !errors.As(err, &ierr) || ierr.Index != 1 || !errors.Is(err, errBoom) {
+		t.Fatalf("expected IndexedError at index 1, got %v", err)
+	}
+}
+
+func TestFold_SkipErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	seq := slices.Values([]Chain[int]{Wrap(1), Wrap(0).WithError(errBoom), Wrap(3)})
+
+	got, err := Fold(seq, 0, add, SkipErrors[int]()).Result()
+	if err != nil || got != 4 {
+		t.Fatalf("expected 4, got %d, %v", got, err)
+	}
+
+	allFailed := slices.Values([]Chain[int]{Wrap(0).WithError(errBoom), Wrap(0).WithError(errBoom)})
+	_, err = Fold(allFailed, 0, add, SkipErrors[int]()).Result()
+	if !errors.Is(err, ErrAllFailed) || !errors.Is(err, errBoom) {
+		t.Fatalf("expected ErrAllFailed, got %v", err)
+	}
+}
+
+func TestFold_StopWhen(t *testing.T) {
+	produced := 0
+	got, err := Fold(naturals(&produced), 0, add, StopWhen(func(acc int) bool { return acc >= 10 })).Result()
+	if err != nil || got != 10 {
+		t.Fatalf("expected 10, got %d, %v", got, err)
+	}
+	if produced != 5 {
+		t.Fatalf("expected the sequence to stop after 5 values, got %d", produced)
+	}
+}
+
+func TestFold_NilFunc(t *testing.T) {
+	pulled := false
+	seq := func(yield func(Chain[int]) bool) {
+		pulled = true
+		yield(Wrap(1))
+	}
+	if _, err := Fold[int, int](seq, 0, nil).Result(); !errors.Is(err, ErrNilFunc) || pulled {
+		t.Fatalf("expected ErrNilFunc without consuming the sequence, got %v", err)
+	}
+	if _, err := Reduce(slices.Values([]Chain[int]{}), nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc on empty input, got %v", err)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	seq := slices.Values([]Chain[int]{Wrap(0).WithError(errors.New("skip")), Wrap(4), Wrap(5)})
+
+	got, err := Reduce(seq, func(a, b int) int { return max(a, b) }, SkipErrors[int]()).Result()
+	if err != nil || got != 5 {
+		t.Fatalf("expected 5, got %d, %v", got, err)
+	}
+
+	if _, err := Reduce(slices.Values([]Chain[int]{}), add).Result(); !errors.Is(err, ErrEmptyInput) {
+		t.Fatalf("expected ErrEmptyInput, got %v", err)
+	}
+}
+
+func TestFold_Options(t *testing.T) {
+	seq := slices.Values([]Chain[int]{Wrap(1, WithPanicSafe(), WithStrict())})
+
+	_, err := Fold(seq, 0, func(int, int) int { panic("boom") }).Result()
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *PanicError, got %v", err)
+	}
+
+	if _, err := Fold[int, int](seq, 0, nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc, got %v", err)
+	}
+}
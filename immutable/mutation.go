@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KeibiSoft/go-fp/internal/deep"
+	"github.com/KeibiSoft/go-fp/internal/shared"
+)
+
+// Cloner is implemented by types that can deep-copy themselves.
+// WithMutationCheck uses it instead of a reflective deep copy; it is the same interface as mutable.Cloner.
+type Cloner[T any] = shared.Cloner[T]
+
+// MutationDetectedError is stored in a chain created with WithMutationCheck
+// when a step changed the value it received, through a shared slice, map or pointer.
+type MutationDetectedError struct {
+	// Paths lists the mutated locations, e.g. "[0].Age" for a []User.
+	Paths []string
+}
+
+func (e *MutationDetectedError) Error() string {
+	return fmt.Sprintf("step mutated its input at %s", strings.Join(e.Paths, ", "))
+}
+
+// WithMutationCheck is a debug mode that copies the value before every step and compares it afterwards,
+// storing a *MutationDetectedError in the chain if the step mutated its input.
+// Values are copied with their Clone method if *T implements Cloner, or with a reflective deep copy.
+// The copy and comparison make every step significantly slower.
+func WithMutationCheck() Option {
+	return func(o *options) {
+		o.mutationCheck = true
+	}
+}
+
+// checkMutation copies v when WithMutationCheck is enabled. The returned function reports
+// a *MutationDetectedError if v was changed since; it is a no-op without the check.
+// The copy and the comparison run under protect, so with WithPanicSafe a panic of either
+// is reported by the returned function instead of unwinding the caller.
+func checkMutation[T any](o options, v T) func() error {
+	if !o.mutationCheck {
+		return func() error { return nil }
+	}
+
+	snap, err := protect(o.panicSafe, v, func() (T, error) {
+		if c, ok := any(&v).(Cloner[T]); ok {
+			return *c.Clone(), nil
+		}
+		return deep.Copy(v), nil
+	})
+	if err != nil {
+		return func() error { return err }
+	}
+
+	return func() error {
+		paths, err := protect(o.panicSafe, nil, func() ([]string, error) {
+			var paths []string
+			deep.Diff(snap, v, func(path string, _, _ any) {
+				paths = append(paths, path)
+			})
+			return paths, nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+		return &MutationDetectedError{Paths: paths}
+	}
+}
+
+// withMutation joins the error reported by check with err.
+func withMutation(err error, check func() error) error {
+	merr := check()
+	if merr == nil {
+		return err
+	}
+	if err == nil {
+		return merr
+	}
+	return errors.Join(err, merr)
+}
@@ -0,0 +1,152 @@
+package chain
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+type User struct {
+	Name string
+	Age  int
+}
+
+func birthdayInPlace(users []User) ([]User, error) {
+	for i := range users {
+		users[i].Age++
+	}
+	return users, nil
+}
+
+func birthdayCopy(users []User) ([]User, error) {
+	out := make([]User, len(users))
+	for i, u := range users {
+		u.Age++
+		out[i] = u
+	}
+	return out, nil
+}
+
+func TestMutationCheck_DetectsSharedSliceMutation(t *testing.T) {
+	users := []User{{Name: "Alice", Age: 30}, {Name: "Bob", Age: 22}}
+
+	_, err := Wrap(users, WithMutationCheck()).
+		Then(birthdayInPlace).
+		Result()
+
+	var me *MutationDetectedError
+	if !errors.As(err, &me) {
+		t.Fatalf("expected *MutationDetectedError, got %v", err)
+	}
+	if len(me.Paths) != 2 || me.Paths[0] != "[0].Age" || me.Paths[1] != "[1].Age" {
+		t.Fatalf("expected mutated paths [[0].Age [1].Age], got %v", me.Paths)
+	}
+}
+
+func TestMutationCheck_AllowsPureSteps(t *testing.T) {
+	users := []User{{Name: "Alice", Age: 30}}
+
+	got, err := Wrap(users, WithMutationCheck()).
+		Then(birthdayCopy).
+		Map(func(us []User) []User { return us[:1] }).
+		Result()
+	if err != nil {
+		t.Fatalf("expected no error for pure steps, got %v", err)
+	}
+	if got[0].Age != 31 || users[0].Age != 30 {
+		t.Fatalf("unexpected values: got %v, original %v", got, users)
+	}
+}
+
+func TestMutationCheck_ThroughBind(t *testing.T) {
+	m := map[string]int{"a": 1}
+	c := Bind(Wrap(m, WithMutationCheck()), func(m map[string]int) Chain[int] {
+		m["a"] = 2
+		return Wrap(len(m))
+	})
+
+	var me *MutationDetectedError
+	if !errors.As(c.err, &me) || me.Paths[0] != "[a]" {
+		t.Fatalf("expected mutation at [a], got %v", c.err)
+	}
+}
+
+type clonable struct {
+	Items  []int
+	clones *int
+}
+
+func (c *clonable) Clone() *clonable {
+	*c.clones++
+	return &clonable{Items: append([]int(nil), c.Items...), clones: c.clones}
+}
+
+func TestMutationCheck_UsesCloner(t *testing.T) {
+	clones := 0
+	v := clonable{Items: []int{1}, clones: &clones}
+
+	_, err := Wrap(v, WithMutationCheck()).
+		Then(func(c clonable) (clonable, error) {
+			c.Items[0] = 2
+			return c, nil
+		}).
+		Result()
+
+	if clones != 1 {
+		t.Fatalf("expected Clone to be used once, got %d", clones)
+	}
+	var me *MutationDetectedError
+	if !errors.As(err, &me) {
+		t.Fatalf("expected *MutationDetectedError, got %v", err)
+	}
+}
+
+func TestMutationCheck_SharedClonerShape(t *testing.T) {
+	clones := 0
+	v := &clonable{Items: []int{1}, clones: &clones}
+	// The same Clone method serves both packages.
+	var _ mutable.Cloner[clonable] = v
+	if cp, err := mutable.CloneCopy(v); err != nil || cp == v || clones != 1 {
+		t.Fatalf("expected mutable.CloneCopy to use Clone, got %v, %v", cp, err)
+	}
+}
+
+func TestMutationCheck_UnexportedFieldsInMaps(t *testing.T) {
+	seen := map[string]time.Time{"a": time.Now()}
+
+	_, err := Wrap(seen, WithMutationCheck()).
+		Then(func(m map[string]time.Time) (map[string]time.Time, error) {
+			return m, nil
+		}).
+		Result()
+	if err != nil {
+		t.Fatalf("expected no mutation, got %v", err)
+	}
+}
+
+type panicCloner struct{ V int }
+
+func (*panicCloner) Clone() *panicCloner { panic("clone failed") }
+
+func TestMutationCheck_PanicSafeSnapshot(t *testing.T) {
+	_, err := Wrap(panicCloner{V: 1}, WithMutationCheck(), WithPanicSafe()).
+		Then(func(v panicCloner) (panicCloner, error) { return v, nil }).
+		Result()
+
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected the snapshot panic as a *PanicError, got %v", err)
+	}
+}
+
+func TestMutationCheck_NaN(t *testing.T) {
+	_, err := Wrap([]float64{math.NaN()}, WithMutationCheck()).
+		Then(func(v []float64) ([]float64, error) { return v, nil }).
+		Result()
+	if err != nil {
+		t.Fatalf("expected an unchanged NaN not to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package chain
+
+import "runtime/debug"
+
+// Option configures a Chain created with Wrap.
+// Options are carried along every step, including Bind, Apply and LiftM.
+type Option func(*options)
+
+type options struct {
+	panicSafe bool
+	strict    bool
+	// mutationCheck enables WithMutationCheck.
+	mutationCheck bool
+}
+
+// WithPanicSafe makes every step of the chain recover from panics raised by user functions,
+// storing them in the chain as a *PanicError instead of unwinding the caller.
+func WithPanicSafe() Option {
+	return func(o *options) {
+		o.panicSafe = true
+	}
+}
+
+// WithStrict reports nil functions passed to steps as ErrNilFunc, instead of silently skipping them.
+// Filter without an error reports ErrPredicateFailed when the predicate does not hold.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// strictErr returns err in strict mode and nil otherwise.
+func (o options) strictErr(err error) error {
+	if o.strict {
+		return err
+	}
+	return nil
+}
+
+// merge combines two option sets, enabling every mode set in either of them.
+func (o options) merge(other options) options {
+	o.panicSafe = o.panicSafe || other.panicSafe
+	o.strict = o.strict || other.strict
+	o.mutationCheck = o.mutationCheck || other.mutationCheck
+	return o
+}
+
+// protect calls fn, converting a panic into a *PanicError when safe is set.
+// On panic the fallback value is returned alongside the error.
+func protect[V any](safe bool, fallback V, fn func() (V, error)) (val V, err error) {
+	if !safe {
+		return fn()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			val, err = fallback, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
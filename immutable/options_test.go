@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+func panicStep(ms MyStruct) (MyStruct, error) {
+	panic("boom")
+}
+
+func TestPanicSafe_Then(t *testing.T) {
+	c := Wrap(MyStruct{Val: 1}, WithPanicSafe()).
+		Then(AddOne). // 2
+		Then(panicStep).
+		Then(AddOne) // skipped
+
+	val, err := c.Result()
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *PanicError, got %v", err)
+	}
+	if pe.Value != "boom" {
+		t.Fatalf("expected panic value 'boom', got %v", pe.Value)
+	}
+	if val.Val != 2 {
+		t.Fatalf("expected previous value 2 to be kept, got %d", val.Val)
+	}
+}
+
+func TestPanicSafe_MapFilter(t *testing.T) {
+	c := Wrap(MyStruct{Val: 1}, WithPanicSafe()).
+		Map(func(ms MyStruct) MyStruct { panic("map") })
+	if _, ok := c.err.(*PanicError); !ok {
+		t.Fatalf("expected *PanicError from Map, got %v", c.err)
+	}
+
+	c2 := Wrap(MyStruct{Val: 1}, WithPanicSafe()).
+		Filter(func(ms MyStruct) bool { panic("filter") }, errors.New("unused"))
+	if _, ok := c2.err.(*PanicError); !ok {
+		t.Fatalf("expected *PanicError from Filter, got %v", c2.err)
+	}
+}
+
+func TestPanicSafe_PropagatesThroughBind(t *testing.T) {
+	c := Wrap(MyStruct{Val: 1}, WithPanicSafe())
+
+	bound := Bind(c, func(ms MyStruct) Chain[int] {
+		return Wrap(ms.Val)
+	})
+	if !bound.opts.panicSafe {
+		t.Fatal("expected panic-safe option to carry over through Bind")
+	}
+
+	res := Bind(bound, func(int) Chain[string] { panic("bind") })
+	if _, ok := res.err.(*PanicError); !ok {
+		t.Fatalf("expected *PanicError from Bind, got %v", res.err)
+	}
+
+	lifted := LiftM(func(int) string { panic("liftm") })(bound)
+	if _, ok := lifted.err.(*PanicError); !ok {
+		t.Fatalf("expected *PanicError from LiftM, got %v", lifted.err)
+	}
+
+	applied := Apply(bound, Wrap(func(int) string { panic("apply") }))
+	if _, ok := applied.err.(*PanicError); !ok {
+		t.Fatalf("expected *PanicError from Apply, got %v", applied.err)
+	}
+}
+
+func BenchmarkThen(b *testing.B) {
+	c := Wrap(MyStruct{Val: 1})
+	for b.Loop() {
+		c.Then(AddOne).Then(MultiplyTwo).Then(AddOne)
+	}
+}
+
+func BenchmarkThen_PanicSafe(b *testing.B) {
+	c := Wrap(MyStruct{Val: 1}, WithPanicSafe())
+	for b.Loop() {
+		c.Then(AddOne).Then(MultiplyTwo).Then(AddOne)
+	}
+}
+
+func TestStrict_NilFunctions(t *testing.T) {
+	c := Wrap(MyStruct{Val: 1}, WithStrict())
+
+	if _, err := c.Then(nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Then(nil), got %v", err)
+	}
+	if _, err := c.Map(nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Map(nil), got %v", err)
+	}
+	if _, err := Bind[MyStruct, int](c, nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Bind, got %v", err)
+	}
+	if _, err := Apply(c, Wrap[func(MyStruct) int](nil)).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Apply, got %v", err)
+	}
+	if _, err := LiftM[MyStruct, int](nil)(c).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from LiftM, got %v", err)
+	}
+
+	// without strict mode nil functions are still skipped
+	if _, err := Wrap(MyStruct{Val: 1}).Then(nil).Result(); err != nil {
+		t.Fatalf("expected no error without strict mode, got %v", err)
+	}
+}
+
+func TestStrict_FilterWithoutError(t *testing.T) {
+	c := Wrap(MyStruct{Val: 1}, WithStrict()).
+		Filter(func(ms MyStruct) bool { return ms.Val > 1 }, nil)
+	if !errors.Is(c.err, ErrPredicateFailed) {
+		t.Fatalf("expected ErrPredicateFailed, got %v", c.err)
+	}
+
+	c2 := Wrap(MyStruct{Val: 1}).
+		Filter(func(ms MyStruct) bool { return ms.Val > 1 }, nil)
+	if c2.err != nil {
+		t.Fatalf("expected no error without strict mode, got %v", c2.err)
+	}
+}
@@ -0,0 +1,44 @@
+package chain
+
+import "github.com/KeibiSoft/go-fp/internal/shared"
+
+// IndexedError is the error of a failed input, along with its index in the input slice.
+// It is the same type in the immutable and mutable packages.
+type IndexedError = shared.IndexedError
+
+// Report describes which inputs of a batch were used and which were skipped and why.
+// It is the same type in the immutable and mutable packages.
+type Report = shared.Report
+
+// Partition splits chains into the values of the successful ones and the errors of the failed ones.
+// Both keep the input order.
+func Partition[T any](chains []Chain[T]) (values []T, errs []IndexedError) {
+	for i, c := range chains {
+		if c.err != nil {
+			errs = append(errs, IndexedError{Index: i, Err: c.err})
+			continue
+		}
+		values = append(values, c.val)
+	}
+	return values, errs
+}
+
+// MapReduceReport works like MapReduceChains, and also reports which chains were skipped and why.
+// Returns zero and an empty report if mapFn or reduceFn is nil.
+func MapReduceReport[T any, R any](chains []Chain[T], mapFn func(T) R, reduceFn func(R, R) R, zero R) (R, Report) {
+	if mapFn == nil || reduceFn == nil {
+		return zero, Report{Total: len(chains)}
+	}
+
+	values, errs := Partition(chains)
+	report := Report{Total: len(chains), Used: len(values), Skipped: errs}
+	if len(values) == 0 {
+		return zero, report
+	}
+
+	result := mapFn(values[0])
+	for _, v := range values[1:] {
+		result = reduceFn(result, mapFn(v))
+	}
+	return result, report
+}
@@ -0,0 +1,60 @@
+package chain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPartition(t *testing.T) {
+	errA := errors.New("a failed")
+	chains := []Chain[int]{
+		Wrap(1),
+		Wrap(0).WithError(errA),
+		Wrap(3),
+	}
+
+	values, errs := Partition(chains)
+	if len(values) != 2 || values[0] != 1 || values[1] != 3 {
+		t.Fatalf("expected [1 3], got %v", values)
+	}
+	if len(errs) != 1 || errs[0].Index != 1 || !errors.Is(errs[0], errA) {
+		t.Fatalf("expected error at index 1, got %v", errs)
+	}
+}
+
+func TestMapReduceReport(t *testing.T) {
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+	chains := []Chain[int]{
+		Wrap(0).WithError(errA),
+		Wrap(2),
+		Wrap(3),
+		Wrap(0).WithError(errB),
+	}
+
+	sum, report := MapReduceReport(chains, func(v int) int { return v * 10 }, func(a, b int) int { return a + b }, -1)
+	if sum != 50 {
+		t.Fatalf("expected 50, got %d", sum)
+	}
+	if report.Total != 4 || report.Used != 2 || len(report.Skipped) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	err := report.Err()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) || !strings.Contains(err.Error(), "index 3") {
+		t.Fatalf("expected joined indexed errors, got %v", err)
+	}
+}
+
+func TestMapReduceReport_AllFailed(t *testing.T) {
+	chains := []Chain[int]{Wrap(0).WithError(errors.New("fail"))}
+
+	sum, report := MapReduceReport(chains, func(v int) int { return v }, func(a, b int) int { return a + b }, -1)
+	if sum != -1 || report.Used != 0 || report.Err() == nil {
+		t.Fatalf("expected zero and a failing report, got %d, %+v", sum, report)
+	}
+
+	if _, report := MapReduceReport(nil, func(v int) int { return v }, func(a, b int) int { return a + b }, 0); report.Err() != nil {
+		t.Fatalf("expected no skipped inputs, got %v", report.Err())
+	}
+}
@@ -0,0 +1,91 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeCloser struct {
+	closed bool
+	err    error
+}
+
+func (f *fakeCloser) Close() error {
+	f.closed = true
+	return f.err
+}
+
+func TestUsing_ReleasesOnSuccessAndError(t *testing.T) {
+	fc := &fakeCloser{}
+	c := Using(func() (*fakeCloser, error) { return fc, nil }, func(*fakeCloser) Chain[int] {
+		return Wrap(1)
+	})
+	if !fc.closed {
+		t.Fatal("expected resource to be closed on success")
+	}
+	if val, err := c.Result(); err != nil || val != 1 {
+		t.Fatalf("expected 1 and no error, got %d, %v", val, err)
+	}
+
+	fc2 := &fakeCloser{}
+	errUse := errors.New("use failed")
+	c2 := Using(func() (*fakeCloser, error) { return fc2, nil }, func(*fakeCloser) Chain[int] {
+		return Wrap(0).WithError(errUse)
+	})
+	if !fc2.closed {
+		t.Fatal("expected resource to be closed on error")
+	}
+	if !errors.Is(c2.err, errUse) {
+		t.Fatalf("expected use error, got %v", c2.err)
+	}
+}
+
+func TestBracket_ReleaseErrorJoined(t *testing.T) {
+	errUse := errors.New("use failed")
+	errClose := errors.New("close failed")
+	fc := &fakeCloser{err: errClose}
+
+	c := Using(func() (*fakeCloser, error) { return fc, nil }, func(*fakeCloser) Chain[int] {
+		return Wrap(0).WithError(errUse)
+	})
+	if !errors.Is(c.err, errUse) || !errors.Is(c.err, errClose) {
+		t.Fatalf("expected both use and close errors, got %v", c.err)
+	}
+}
+
+func TestBracket_ReleasesOnPanic(t *testing.T) {
+	released := false
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic to be re-raised")
+		}
+		if !released {
+			t.Fatal("expected resource to be released on panic")
+		}
+	}()
+
+	Bracket(func() (int, error) { return 1, nil }, func(int) Chain[int] {
+		panic("boom")
+	}, func(int) error {
+		released = true
+		return nil
+	})
+}
+
+func TestBracket_AcquireFails(t *testing.T) {
+	errAcquire := errors.New("acquire failed")
+	c := Bracket(func() (int, error) { return 0, errAcquire }, func(int) Chain[int] {
+		t.Fatal("use should not be called when acquire fails")
+		return Chain[int]{}
+	}, func(int) error {
+		t.Fatal("release should not be called when acquire fails")
+		return nil
+	})
+	if c.err != errAcquire {
+		t.Fatalf("expected acquire error, got %v", c.err)
+	}
+
+	if c := Bracket[int, int](nil, nil, nil); !errors.Is(c.err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc, got %v", c.err)
+	}
+}
@@ -0,0 +1,125 @@
+package chain
+
+import "iter"
+
+// FromSeq2 turns a sequence of (value, error) pairs into a sequence of chains.
+// Each pair becomes a chain holding the value, failed if the error is not nil.
+// A nil seq yields nothing.
+func FromSeq2[T any](seq iter.Seq2[T, error]) iter.Seq[Chain[T]] {
+	return func(yield func(Chain[T]) bool) {
+		if seq == nil {
+			return
+		}
+		for v, err := range seq {
+			if !yield(Chain[T]{val: v, err: err}) {
+				return
+			}
+		}
+	}
+}
+
+// ToSeq2 turns a sequence of chains into a sequence of (value, error) pairs.
+// Deferred actions of the chains are not run.
+func ToSeq2[T any](seq iter.Seq[Chain[T]]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if seq == nil {
+			return
+		}
+		for c := range seq {
+			if !yield(c.val, c.err) {
+				return
+			}
+		}
+	}
+}
+
+// Collect gathers the values of seq into a single chain, stopping at the first failed chain.
+// The returned chain carries the merged options and deferred actions of every chain consumed,
+// and holds the error of the failed chain if there was one.
+// A nil or empty seq gives an empty, successful chain.
+func Collect[T any](seq iter.Seq[Chain[T]]) Chain[[]T] {
+	var out Chain[[]T]
+	if seq == nil {
+		return out
+	}
+	for c := range seq {
+		out.opts = out.opts.merge(c.opts)
+		out.defers = c.defers.then(out.defers)
+		if c.err != nil {
+			out.val, out.err = nil, c.err
+			return out
+		}
+		out.val = append(out.val, c.val)
+	}
+	return out
+}
+
+// MapSeq lazily applies f to the value of every successful chain of seq, like Bind on each of them.
+// Failed chains pass through with their error, and an error returned by f fails the mapped chain.
+// Options of each chain apply, so panic-safe mode behaves as in Bind.
+// If f is nil, every successful chain fails with ErrNilFunc.
+func MapSeq[T any, U any](seq iter.Seq[Chain[T]], f func(T) (U, error)) iter.Seq[Chain[U]] {
+	step := func(v T) Chain[U] {
+		if f == nil {
+			return Chain[U]{err: ErrNilFunc}
+		}
+		u, err := f(v)
+		return Chain[U]{val: u, err: err}
+	}
+	return func(yield func(Chain[U]) bool) {
+		if seq == nil {
+			return
+		}
+		for c := range seq {
+			if !yield(Bind(c, step)) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq lazily drops the successful chains of seq for which pred does not hold.
+// Failed chains are kept, so their errors still reach the consumer.
+// With WithPanicSafe, a panic in pred fails the chain with a *PanicError instead of dropping it.
+// If pred is nil, seq is returned unchanged.
+func FilterSeq[T any](seq iter.Seq[Chain[T]], pred func(T) bool) iter.Seq[Chain[T]] {
+	if pred == nil {
+		return seq
+	}
+	return func(yield func(Chain[T]) bool) {
+		if seq == nil {
+			return
+		}
+		for c := range seq {
+			if c.err == nil {
+				ok, err := protect(c.opts.panicSafe, false, func() (bool, error) {
+					return pred(c.val), nil
+				})
+				if err != nil {
+					c = Chain[T]{val: c.val, err: err, opts: c.opts, defers: c.defers}
+				} else if !ok {
+					continue
+				}
+			}
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// TakeWhileOk lazily yields the chains of seq up to, but not including, the first failed one.
+// The underlying sequence is not consumed any further after a failure.
+// Use Collect instead when the error itself is needed.
+func TakeWhileOk[T any](seq iter.Seq[Chain[T]]) iter.Seq[Chain[T]] {
+	return func(yield func(Chain[T]) bool) {
+		if seq == nil {
+			return
+		}
+		for c := range seq {
+			if c.err != nil || !yield(c) {
+				return
+			}
+		}
+	}
+}
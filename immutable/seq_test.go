@@ -0,0 +1,185 @@
+package chain
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// naturals yields 0, 1, 2, ... forever, counting how many values were produced.
+func naturals(produced *int) iter.Seq[Chain[int]] {
+	return func(yield func(Chain[int]) bool) {
+		for i := 0; ; i++ {
+			*produced++
+			if !yield(Wrap(i)) {
+				return
+			}
+		}
+	}
+}
+
+func pairs(items ...string) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for _, s := range items {
+			if !yield(strconv.Atoi(s)) {
+				return
+			}
+		}
+	}
+}
+
+func TestFromSeq2_Collect(t *testing.T) {
+	got, err := Collect(FromSeq2(pairs("1", "2", "3"))).Result()
+	if err != nil || !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v, %v", got, err)
+	}
+
+	_, err = Collect(FromSeq2(pairs("1", "x", "3"))).Result()
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatalf("expected *strconv.NumError, got %v", err)
+	}
+
+	if got, err := Collect[int](nil).Result(); err != nil || got != nil {
+		t.Fatalf("expected empty chain, got %v, %v", got, err)
+	}
+}
+
+func TestCollect_MergesDefers(t *testing.T) {
+	var order []int
+	seq := func(yield func(Chain[int]) bool) {
+		for i := range 2 {
+			c := Wrap(i).Defer(func() error {
+				order = append(order, i)
+				return nil
+			})
+			if !yield(c) {
+				return
+			}
+		}
+	}
+
+	if _, err := Collect(seq).Result(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !slices.Equal(order, []int{1, 0}) {
+		t.Fatalf("expected deferred actions in LIFO order, got %v", order)
+	}
+}
+
+func TestToSeq2(t *testing.T) {
+	errBoom := errors.New("boom")
+	seq := slices.Values([]Chain[int]{Wrap(1), Wrap(0).WithError(errBoom)})
+
+	var vals []int
+	var errs []error
+	for v, err := range ToSeq2(seq) {
+		vals = append(vals, v)
+		errs = append(errs, err)
+	}
+	if !slices.Equal(vals, []int{1, 0}) || errs[0] != nil || !errors.Is(errs[1], errBoom) {
+		t.Fatalf("unexpected pairs %v, %v", vals, errs)
+	}
+}
+
+func TestMapFilterSeq_Lazy(t *testing.T) {
+	produced := 0
+	even := FilterSeq(naturals(&produced), func(v int) bool { return v%2 == 0 })
+	squares := MapSeq(even, func(v int) (int, error) { return v * v, nil })
+
+	var got []int
+	for c := range squares {
+		got = append(got, c.Unwrap())
+		if len(got) == 3 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{0, 4, 16}) {
+		t.Fatalf("expected [0 4 16], got %v", got)
+	}
+	if produced != 5 {
+		t.Fatalf("expected 5 values produced, got %d", produced)
+	}
+}
+
+func TestMapSeq_Errors(t *testing.T) {
+	errOdd := errors.New("odd")
+	errIn := errors.New("input")
+	seq := slices.Values([]Chain[int]{Wrap(2), Wrap(3), Wrap(0).WithError(errIn)})
+
+	var errs []error
+	for c := range MapSeq(seq, func(v int) (string, error) {
+		if v%2 == 1 {
+			return "", errOdd
+		}
+		return strconv.Itoa(v), nil
+	}) {
+		errs = append(errs, c.HasError())
+	}
+	if errs[0] != nil || !errors.Is(errs[1], errOdd) || !errors.Is(errs[2], errIn) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+
+	// Failed chains are kept by FilterSeq.
+	n := 0
+	for range FilterSeq(seq, func(int) bool { return false }) {
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("expected only the failed chain to be kept, got %d", n)
+	}
+}
+
+func TestMapSeq_PanicSafe(t *testing.T) {
+	seq := slices.Values([]Chain[int]{Wrap(1, WithPanicSafe())})
+	for c := range MapSeq(seq, func(int) (int, error) { panic("boom") }) {
+		var pe *PanicError
+		if !errors.As(c.HasError(), &pe) {
+			t.Fatalf("expected *PanicError, got %v", c.HasError())
+		}
+	}
+}
+
+func TestMapSeq_NilFunc(t *testing.T) {
+	seq := slices.Values([]Chain[int]{Wrap(1)})
+	for c := range MapSeq[int, string](seq, nil) {
+		if !errors.Is(c.HasError(), ErrNilFunc) {
+			t.Fatalf("expected ErrNilFunc, got %v", c.HasError())
+		}
+	}
+}
+
+func TestFilterSeq_PanicSafe(t *testing.T) {
+	seq := slices.Values([]Chain[int]{Wrap(1, WithPanicSafe())})
+	n := 0
+	for c := range FilterSeq(seq, func(int) bool { panic("boom") }) {
+		n++
+		var pe *PanicError
+		if !errors.As(c.HasError(), &pe) {
+			t.Fatalf("expected *PanicError, got %v", c.HasError())
+		}
+	}
+	if n != 1 {
+		t.Fatalf("expected the panicking chain to be kept as a failure, got %d", n)
+	}
+}
+
+func TestTakeWhileOk(t *testing.T) {
+	produced := 0
+	failing := MapSeq(naturals(&produced), func(v int) (int, error) {
+		if v == 3 {
+			return 0, errors.New("stop")
+		}
+		return v, nil
+	})
+
+	got, err := Collect(TakeWhileOk(failing)).Result()
+	if err != nil || !slices.Equal(got, []int{0, 1, 2}) {
+		t.Fatalf("expected [0 1 2], got %v, %v", got, err)
+	}
+	if produced != 4 {
+		t.Fatalf("expected production to stop at the failure, got %d", produced)
+	}
+}
@@ -0,0 +1,207 @@
+// Package deep implements reflective deep copies and field-level diffs of Go values.
+// Unexported fields are copied and compared as well.
+package deep
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Copy returns a deep copy of v. Pointer cycles and shared pointers are preserved.
+// Functions, channels and unsafe pointers are copied by reference, and map keys are kept as is,
+// since a copied pointer key would no longer match.
+func Copy[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	copyValue(dst, src, map[pointer]reflect.Value{})
+	return dst.Interface().(T)
+}
+
+// pointer identifies a pointer by address and type: a struct and its first field,
+// or two zero-size values, may share an address.
+type pointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// accessible returns v with the read-only flag of unexported fields cleared.
+// v must be addressable.
+func accessible(v reflect.Value) reflect.Value {
+	if v.CanInterface() || !v.CanAddr() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// addressable returns an addressable copy of v, so accessible can be applied to its fields.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	return cp
+}
+
+func copyValue(dst, src reflect.Value, seen map[pointer]reflect.Value) {
+	dst, src = accessible(dst), accessible(src)
+
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		key := pointer{addr: src.Pointer(), typ: src.Type()}
+		if p, ok := seen[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		seen[key] = p
+		copyValue(p.Elem(), src.Elem(), seen)
+		dst.Set(p)
+	case reflect.Struct:
+		for i := range src.NumField() {
+			copyValue(dst.Field(i), src.Field(i), seen)
+		}
+	case reflect.Array:
+		for i := range src.Len() {
+			copyValue(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := range src.Len() {
+			copyValue(s.Index(i), src.Index(i), seen)
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			e := reflect.New(src.Type().Elem()).Elem()
+			copyValue(e, addressable(iter.Value()), seen)
+			m.SetMapIndex(iter.Key(), e)
+		}
+		dst.Set(m)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		e := reflect.New(src.Elem().Type()).Elem()
+		copyValue(e, addressable(src.Elem()), seen)
+		dst.Set(e)
+	default:
+		dst.Set(src)
+	}
+}
+
+// Diff walks a and b, which must have the same type, and calls fn for every leaf that differs.
+// The path uses Go syntax relative to the root, e.g. "Users[2].Name" or "Tags[admin]".
+// Values missing on one side, such as removed slice elements or map keys, are reported as nil.
+func Diff[T any](a, b T, fn func(path string, old, new any)) {
+	va := reflect.ValueOf(&a).Elem()
+	vb := reflect.ValueOf(&b).Elem()
+	diffValue("", va, vb, fn, map[[2]pointer]bool{})
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func iface(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return accessible(addressable(v)).Interface()
+}
+
+func diffValue(path string, a, b reflect.Value, fn func(string, any, any), seen map[[2]pointer]bool) {
+	a, b = accessible(a), accessible(b)
+
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				fn(path, iface(a), iface(b))
+			}
+			return
+		}
+		key := [2]pointer{{a.Pointer(), a.Type()}, {b.Pointer(), b.Type()}}
+		if a.Pointer() == b.Pointer() || seen[key] {
+			return
+		}
+		seen[key] = true
+		diffValue(path, a.Elem(), b.Elem(), fn, seen)
+	case reflect.Struct:
+		for i := range a.NumField() {
+			diffValue(join(path, a.Type().Field(i).Name), a.Field(i), b.Field(i), fn, seen)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := range max(a.Len(), b.Len()) {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				fn(p, nil, iface(b.Index(i)))
+			case i >= b.Len():
+				fn(p, iface(a.Index(i)), nil)
+			default:
+				diffValue(p, a.Index(i), b.Index(i), fn, seen)
+			}
+		}
+	case reflect.Map:
+		for _, k := range a.MapKeys() {
+			p := fmt.Sprintf("%s[%v]", path, iface(k))
+			bv := b.MapIndex(k)
+			if !bv.IsValid() {
+				fn(p, iface(a.MapIndex(k)), nil)
+				continue
+			}
+			diffValue(p, addressable(a.MapIndex(k)), addressable(bv), fn, seen)
+		}
+		for _, k := range b.MapKeys() {
+			if !a.MapIndex(k).IsValid() {
+				fn(fmt.Sprintf("%s[%v]", path, iface(k)), nil, iface(b.MapIndex(k)))
+			}
+		}
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() || a.Elem().Type() != b.Elem().Type() {
+			if !a.IsNil() || !b.IsNil() {
+				fn(path, iface(a), iface(b))
+			}
+			return
+		}
+		diffValue(path, addressable(a.Elem()), addressable(b.Elem()), fn, seen)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			fn(path, iface(a), iface(b))
+		}
+	case reflect.Float32, reflect.Float64:
+		if !sameFloat(a.Float(), b.Float()) {
+			fn(path, iface(a), iface(b))
+		}
+	case reflect.Complex64, reflect.Complex128:
+		ca, cb := a.Complex(), b.Complex()
+		if !sameFloat(real(ca), real(cb)) || !sameFloat(imag(ca), imag(cb)) {
+			fn(path, iface(a), iface(b))
+		}
+	default:
+		if !a.Equal(b) {
+			fn(path, iface(a), iface(b))
+		}
+	}
+}
+
+// sameFloat reports whether a and b are equal, treating NaN as equal to itself.
+func sameFloat(a, b float64) bool {
+	return a == b || (a != a && b != b)
+}
@@ -0,0 +1,173 @@
+package deep
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type inner struct {
+	Name string
+	tags []string
+}
+
+type outer struct {
+	ID    int
+	Items []inner
+	Attrs map[string]int
+	Ptr   *inner
+	Any   any
+	self  *outer
+}
+
+func TestCopy_IsDeep(t *testing.T) {
+	o := &outer{
+		ID:    1,
+		Items: []inner{{Name: "a", tags: []string{"x"}}},
+		Attrs: map[string]int{"k": 1},
+		Ptr:   &inner{Name: "p"},
+		Any:   []int{1, 2},
+	}
+	o.self = o
+
+	cp := Copy(o)
+	if !reflect.DeepEqual(o, cp) {
+		t.Fatalf("expected copy to be equal, got %+v", cp)
+	}
+	if cp == o || cp.Ptr == o.Ptr {
+		t.Fatal("expected pointers to be copied")
+	}
+	if cp.self != cp {
+		t.Fatal("expected pointer cycles to be preserved")
+	}
+
+	cp.Items[0].tags[0] = "y"
+	cp.Attrs["k"] = 2
+	cp.Any.([]int)[0] = 9
+	if o.Items[0].tags[0] != "x" || o.Attrs["k"] != 1 || o.Any.([]int)[0] != 1 {
+		t.Fatal("expected original to be unaffected by changes to the copy")
+	}
+}
+
+func TestCopy_UnexportedInMapsAndInterfaces(t *testing.T) {
+	now := time.Now()
+	times := map[time.Time]time.Time{now: now}
+	if cp := Copy(times); len(cp) != 1 || !cp[now].Equal(now) {
+		t.Fatalf("expected map values to be copied, got %v", cp)
+	}
+
+	type holder struct{ seen map[string]time.Time }
+	if cp := Copy(holder{seen: map[string]time.Time{"a": now}}); !cp.seen["a"].Equal(now) {
+		t.Fatalf("expected unexported maps to be copied, got %v", cp)
+	}
+
+	var v any = now
+	if cp := Copy(v); !cp.(time.Time).Equal(now) {
+		t.Fatalf("expected interface values to be copied, got %v", cp)
+	}
+
+	o := outer{Any: inner{Name: "i", tags: []string{"x"}}}
+	cp := Copy(o)
+	cp.Any.(inner).tags[0] = "y"
+	if o.Any.(inner).tags[0] != "x" {
+		t.Fatal("expected unexported fields behind an interface to be copied deeply")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := outer{
+		ID:    1,
+		Items: []inner{{Name: "a"}, {Name: "b"}},
+		Attrs: map[string]int{"k": 1, "gone": 2},
+	}
+	b := Copy(a)
+	b.ID = 2
+	b.Items[1].Name = "c"
+	b.Items = append(b.Items, inner{Name: "d"})
+	b.Attrs["k"] = 5
+	delete(b.Attrs, "gone")
+	b.Ptr = &inner{}
+
+	got := map[string][2]any{}
+	Diff(a, b, func(path string, old, new any) {
+		got[path] = [2]any{old, new}
+	})
+
+	want := map[string][2]any{
+		"ID":            {1, 2},
+		"Items[1].Name": {"b", "c"},
+		"Items[2]":      {nil, inner{Name: "d"}},
+		"Attrs[k]":      {1, 5},
+		"Attrs[gone]":   {2, nil},
+	}
+	for path, w := range want {
+		if g, ok := got[path]; !ok || !reflect.DeepEqual(g, w) {
+			t.Fatalf("expected %s to change %v, got %v", path, w, g)
+		}
+	}
+	if _, ok := got["Ptr"]; !ok {
+		t.Fatal("expected nil to non-nil pointer change to be reported")
+	}
+	if len(got) != len(want)+1 {
+		t.Fatalf("unexpected changes: %v", got)
+	}
+}
+
+func TestDiff_UnexportedFields(t *testing.T) {
+	a := inner{tags: []string{"x"}}
+	b := inner{tags: []string{"y"}}
+
+	var paths []string
+	Diff(a, b, func(path string, _, _ any) {
+		paths = append(paths, path)
+	})
+	if len(paths) != 1 || paths[0] != "tags[0]" {
+		t.Fatalf("expected change at tags[0], got %v", paths)
+	}
+}
+
+func TestCopy_PointersSharingAnAddress(t *testing.T) {
+	type pair struct {
+		Whole *inner
+		First *string
+	}
+	in := &inner{Name: "a"}
+	cp := Copy(pair{Whole: in, First: &in.Name})
+	if cp.Whole.Name != "a" || *cp.First != "a" {
+		t.Fatalf("unexpected copy %+v", cp)
+	}
+
+	type empty struct{}
+	type other struct{}
+	type zeros struct {
+		A *empty
+		B *other
+	}
+	if cp := Copy(zeros{A: &empty{}, B: &other{}}); cp.A == nil || cp.B == nil {
+		t.Fatalf("expected both zero-size pointers to be copied, got %+v", cp)
+	}
+}
+
+func TestDiff_NaN(t *testing.T) {
+	type floats struct {
+		F float64
+		C complex128
+	}
+	nan := math.NaN()
+	a := []floats{{F: nan, C: complex(nan, 1)}}
+	var paths []string
+	Diff(a, Copy(a), func(path string, _, _ any) {
+		paths = append(paths, path)
+	})
+	if len(paths) != 0 {
+		t.Fatalf("expected unchanged NaNs not to be reported, got %v", paths)
+	}
+
+	Diff(a, []floats{{F: 1, C: complex(nan, 1)}}, func(path string, _, _ any) {
+		paths = append(paths, path)
+	})
+	if len(paths) != 1 || paths[0] != "[0].F" {
+		t.Fatalf("expected a change at [0].F, got %v", paths)
+	}
+}
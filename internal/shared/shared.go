@@ -0,0 +1,70 @@
+// Package shared defines the types exposed by both the immutable and the mutable package.
+// Each package re-exports them as aliases, so that values from one package are usable with the other.
+package shared
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors shared by the immutable and the mutable package.
+var (
+	// ErrNilFunc is reported when a step is given a nil function.
+	ErrNilFunc = errors.New("nil function")
+	// ErrNilValue is reported when a step is given a nil value.
+	ErrNilValue = errors.New("nil value")
+)
+
+// Cloner is implemented by types that can deep-copy themselves.
+type Cloner[T any] interface {
+	Clone() *T
+}
+
+// PanicError is the error stored in a chain when a panic is recovered.
+// It keeps the original panic value and the stack trace of the panicking goroutine.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic recovered: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is itself an error, otherwise nil.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// IndexedError is the error of a failed input, along with its index in the input slice.
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
+func (e IndexedError) Error() string {
+	return fmt.Sprintf("index %d: %v", e.Index, e.Err)
+}
+
+func (e IndexedError) Unwrap() error {
+	return e.Err
+}
+
+// Report describes which inputs of a batch were used and which were skipped and why.
+type Report struct {
+	Total   int
+	Used    int
+	Skipped []IndexedError
+}
+
+// Err joins the errors of the skipped inputs, or returns nil if none were skipped.
+func (r Report) Err() error {
+	errs := make([]error, 0, len(r.Skipped))
+	for _, s := range r.Skipped {
+		errs = append(errs, s)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/KeibiSoft/go-fp/internal/deep"
+)
+
+// Change is a single field-level difference of the value made by a step.
+// Path uses Go syntax relative to the value, e.g. "Users[2].Name".
+// Old is nil for added elements and New is nil for removed ones.
+type Change struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// AuditRecord lists the changes made by one successful Then or Map step.
+// Steps are numbered from 1 in the order they ran on the wrapper.
+type AuditRecord struct {
+	Step    int      `json:"step"`
+	Changes []Change `json:"changes"`
+}
+
+// AuditSink receives the audit records of a wrapper created with WithAudit.
+// An error returned by Record fails the wrapper.
+type AuditSink interface {
+	Record(rec AuditRecord) error
+}
+
+type auditor struct {
+	sink AuditSink
+	step atomic.Int64
+}
+
+// WithAudit records a reflective field-level diff of the value around every successful Then or Map step.
+// The value is deep-copied before each step, unexported fields included, which makes auditing costly
+// for large values. The option is ignored if sink is nil.
+func WithAudit(sink AuditSink) Option {
+	return func(o *options) {
+		if sink == nil {
+			return
+		}
+		o.audit = &auditor{sink: sink}
+	}
+}
+
+// audit deep-copies the value when WithAudit is enabled. The returned function diffs the copy
+// against the value after the step and records the changes; it is a no-op without auditing.
+func (w Wrapper[T]) audit() func(after *T) error {
+	a := w.opts.audit
+	if a == nil {
+		return func(*T) error { return nil }
+	}
+
+	before := deep.Copy(w.val)
+	return func(after *T) error {
+		rec := AuditRecord{Step: int(a.step.Add(1)), Changes: []Change{}}
+		deep.Diff(before, after, func(path string, old, new any) {
+			rec.Changes = append(rec.Changes, Change{Path: path, Old: old, New: deep.Copy(new)})
+		})
+		return a.sink.Record(rec)
+	}
+}
+
+// MemorySink keeps audit records in memory. It is safe for concurrent use.
+type MemorySink struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (s *MemorySink) Record(rec AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	return nil
+}
+
+// Records returns the audit records received so far.
+func (s *MemorySink) Records() []AuditRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AuditRecord(nil), s.records...)
+}
+
+// JSONLinesSink writes every audit record as one JSON object per line. It is safe for concurrent use.
+type JSONLinesSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONLinesSink returns a sink writing JSON lines to w.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{enc: json.NewEncoder(w)}
+}
+
+func (s *JSONLinesSink) Record(rec AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(rec)
+}
@@ -0,0 +1,112 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type Profile struct {
+	Name  string
+	Roles []string
+}
+
+func TestWithAudit_RecordsChanges(t *testing.T) {
+	sink := &MemorySink{}
+	p := &Profile{Name: "alice", Roles: []string{"user"}}
+
+	_, err := New(p, nil, WithAudit(sink)).
+		Then(func(p *Profile) (*Profile, error) {
+			p.Name = "Alice"
+			return p, nil
+		}).
+		Map(func(p *Profile) {
+			p.Roles = append(p.Roles, "admin")
+		}).
+		Map(func(*Profile) {}).
+		Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	recs := sink.Records()
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 audit records, got %d", len(recs))
+	}
+	if c := recs[0].Changes; recs[0].Step != 1 || len(c) != 1 || c[0].Path != "Name" || c[0].Old != "alice" || c[0].New != "Alice" {
+		t.Fatalf("unexpected first record: %+v", recs[0])
+	}
+	if c := recs[1].Changes; len(c) != 1 || c[0].Path != "Roles[1]" || c[0].Old != nil || c[0].New != "admin" {
+		t.Fatalf("unexpected second record: %+v", recs[1])
+	}
+	if len(recs[2].Changes) != 0 {
+		t.Fatalf("expected no changes for a no-op step, got %+v", recs[2])
+	}
+}
+
+func TestWithAudit_FailedStepNotRecorded(t *testing.T) {
+	sink := &MemorySink{}
+	New(&Profile{}, nil, WithAudit(sink)).
+		Then(func(p *Profile) (*Profile, error) {
+			p.Name = "x"
+			return nil, errors.New("fail")
+		})
+
+	if len(sink.Records()) != 0 {
+		t.Fatalf("expected no records for failed step, got %+v", sink.Records())
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) Record(AuditRecord) error {
+	return errors.New("sink unavailable")
+}
+
+func TestWithAudit_SinkErrorFailsWrapper(t *testing.T) {
+	_, err := New(&Profile{}, nil, WithAudit(failingSink{})).
+		Map(func(p *Profile) { p.Name = "x" }).
+		Result()
+	if err == nil || err.Error() != "sink unavailable" {
+		t.Fatalf("expected sink error, got %v", err)
+	}
+}
+
+func TestWithAudit_SinkErrorRollsBack(t *testing.T) {
+	p := &Profile{Name: "a"}
+	w := New(p, nil, WithAudit(failingSink{}), WithRollback(DeepCopy[Profile]))
+
+	if err := w.Then(func(p *Profile) (*Profile, error) {
+		p.Name = "b"
+		return p, nil
+	}).HasError(); err == nil {
+		t.Fatal("expected the sink error")
+	}
+	if err := w.Map(func(p *Profile) { p.Roles = []string{"admin"} }).HasError(); err == nil {
+		t.Fatal("expected the sink error")
+	}
+	if p.Name != "a" || p.Roles != nil {
+		t.Fatalf("expected both steps to be rolled back, got %+v", p)
+	}
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var buf bytes.Buffer
+	New(&Profile{Name: "a"}, nil, WithAudit(NewJSONLinesSink(&buf))).
+		Map(func(p *Profile) { p.Name = "b" }).
+		Map(func(p *Profile) { p.Name = "c" })
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %q", buf.String())
+	}
+	var rec AuditRecord
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+	if rec.Step != 2 || len(rec.Changes) != 1 || rec.Changes[0].New != "c" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+}
@@ -1,6 +1,9 @@
 package chain
 
-import "fmt"
+import (
+	"fmt"
+	"runtime/debug"
+)
 
 // Wrapper provides a chainable wrapper for pointers to T with error handling.
 // It supports chaining methods returning (*T, error) in a semi-functional style.
@@ -8,11 +11,17 @@ type Wrapper[T any] struct {
 	val        *T
 	err        error
 	errHandler func(error) error
+	opts       options
+	defers     *cleanup
+	undo       *cleanup
 }
 
 // New creates a new Wrapper with an initial value and an optional error handler.
-func New[T any](val *T, errHandler func(error) error) Wrapper[T] {
-	return Wrapper[T]{val, nil, errHandler}
+// Options such as WithPanicSafe apply to every subsequent step.
+// With WithHistory, the initial value is the first history entry.
+func New[T any](val *T, errHandler func(error) error, opts ...Option) Wrapper[T] {
+	w := Wrapper[T]{val: val, errHandler: errHandler, opts: newOptions(opts)}
+	return w.record()
 }
 
 func (w *Wrapper[T]) WithError(err error) *Wrapper[T] {
@@ -22,55 +31,171 @@ func (w *Wrapper[T]) WithError(err error) *Wrapper[T] {
 
 // Then calls fn if no error yet, else skips.
 // If fn returns error, it is passed to errHandler, which can modify or suppress it.
-// If fn is nil, just return the current wrapper unchanged.
+// If fn is nil, just return the current wrapper unchanged, or ErrNilFunc in strict mode.
 func (w Wrapper[T]) Then(fn func(*T) (*T, error)) Wrapper[T] {
-	if w.err != nil || fn == nil {
+	return w.then(fn, w.opts.nilGuard)
+}
+
+// ThenNonNil behaves like Then, but fails with ErrNilValue if the value passed to fn
+// or returned by it is nil, regardless of WithNilGuard.
+func (w Wrapper[T]) ThenNonNil(fn func(*T) (*T, error)) Wrapper[T] {
+	return w.then(fn, true)
+}
+
+func (w Wrapper[T]) then(fn func(*T) (*T, error), guard bool) Wrapper[T] {
+	if w.err != nil {
 		return w
 	}
-	newVal, err := fn(w.val)
+	if fn == nil {
+		return w.nilFunc()
+	}
+	if guard && w.val == nil {
+		return w.fail(ErrNilValue)
+	}
+	restore, err := w.snapshot()
 	if err != nil {
-		if w.errHandler != nil {
-			err = w.errHandler(err)
-		}
-		if err != nil {
-			// preserve previous value to avoid nil deref
-			return Wrapper[T]{w.val, err, w.errHandler}
-		}
-		// errHandler returned nil, continue with old value
-		return w
+		return w.fail(err)
+	}
+	audit := w.audit()
+	newVal, err := protect(w.opts.panicSafe, w.val, func() (*T, error) {
+		return fn(w.val)
+	})
+	if err != nil {
+		restore()
+		return w.fail(err)
+	}
+	if guard && newVal == nil {
+		restore()
+		return w.fail(ErrNilValue)
 	}
-	return Wrapper[T]{newVal, nil, w.errHandler}
+	if err := audit(newVal); err != nil {
+		restore()
+		return w.fail(err)
+	}
+	next := Wrapper[T]{val: newVal, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
+	return next.record()
+}
+
+// fail passes err to errHandler, which can modify or suppress it.
+// If the error is kept, the compensations registered by ThenCompensate run.
+func (w Wrapper[T]) fail(err error) Wrapper[T] {
+	if w.errHandler != nil {
+		err = w.errHandler(err)
+	}
+	if err != nil {
+		// preserve previous value to avoid nil deref, undo registered compensations
+		return Wrapper[T]{val: w.val, err: w.undo.compensate(err), errHandler: w.errHandler, opts: w.opts, defers: w.defers}
+	}
+	// errHandler returned nil, continue with old value
+	return w
+}
+
+// failAs fails w with err like fail, for steps producing a Wrapper of another type.
+// If errHandler suppresses err, the result holds a nil value and no error.
+func failAs[U, T any](w Wrapper[T], err error) Wrapper[U] {
+	f := w.fail(err)
+	return Wrapper[U]{err: f.err, errHandler: f.errHandler, opts: f.opts, defers: f.defers, undo: f.undo}
+}
+
+// guardNil fails w with ErrNilValue if the nil guard is enabled and w holds a nil value.
+func guardNil[T any](w Wrapper[T]) Wrapper[T] {
+	if w.opts.nilGuard && w.err == nil && w.val == nil {
+		return w.fail(ErrNilValue)
+	}
+	return w
+}
+
+// nilFunc returns w unchanged, or fails with ErrNilFunc in strict mode.
+func (w Wrapper[T]) nilFunc() Wrapper[T] {
+	if w.opts.strict {
+		return w.fail(ErrNilFunc)
+	}
+	return w
 }
 
 // Result returns the wrapped value and the last error encountered.
+// It runs the deferred actions first, joining their errors with the wrapper error.
 func (w Wrapper[T]) Result() (*T, error) {
-	return w.val, w.err
+	return w.val, w.finish()
 }
 
 // Map applies a side-effecting function to the wrapped value if no error.
 func (w Wrapper[T]) Map(f func(*T)) Wrapper[T] {
+	return w.mapValue(f, w.opts.nilGuard)
+}
+
+// MapNonNil behaves like Map, but fails with ErrNilValue instead of calling f with a nil value,
+// regardless of WithNilGuard.
+func (w Wrapper[T]) MapNonNil(f func(*T)) Wrapper[T] {
+	return w.mapValue(f, true)
+}
+
+func (w Wrapper[T]) mapValue(f func(*T), guard bool) Wrapper[T] {
 	if w.err != nil {
 		return w
 	}
-	f(w.val)
-	return w
+	if f == nil && w.opts.strict {
+		return w.nilFunc()
+	}
+	if guard && w.val == nil {
+		return w.fail(ErrNilValue)
+	}
+	restore, err := w.snapshot()
+	if err != nil {
+		return w.fail(err)
+	}
+	audit := w.audit()
+	_, err = protect(w.opts.panicSafe, struct{}{}, func() (struct{}, error) {
+		f(w.val)
+		return struct{}{}, nil
+	})
+	if err != nil {
+		restore()
+		return w.fail(err)
+	}
+	if err := audit(w.val); err != nil {
+		restore()
+		return w.fail(err)
+	}
+	return w.record()
 }
 
 // FlatMap allows chaining with functions returning Wrapper[T].
+// The options of w are merged into the returned wrapper.
+// If the returned wrapper failed, the compensations registered by ThenCompensate run.
 func (w Wrapper[T]) FlatMap(f func(*T) Wrapper[T]) Wrapper[T] {
 	if w.err != nil {
 		return w
 	}
-	return f(w.val)
+	if f == nil && w.opts.strict {
+		return w.nilFunc()
+	}
+	if w.opts.nilGuard && w.val == nil {
+		return w.fail(ErrNilValue)
+	}
+	next, err := protect(w.opts.panicSafe, Wrapper[T]{}, func() (Wrapper[T], error) {
+		return f(w.val), nil
+	})
+	if err != nil {
+		return w.fail(err)
+	}
+	next.opts = w.opts.merge(next.opts)
+	next.defers = next.defers.then(w.defers)
+	next.undo = next.undo.then(w.undo)
+	if next.err != nil {
+		next.err, next.undo = next.undo.compensate(next.err), nil
+	}
+	return guardNil(next)
 }
 
 // Match invokes success with the value if no error,
 // otherwise invokes failure with the error.
 // Nil functions are safely ignored.
+// It runs the deferred actions first, a failing action makes the wrapper a failure.
 func (w Wrapper[T]) Match(success func(*T), failure func(error)) {
-	if w.err != nil {
+	if err := w.finish(); err != nil {
 		if failure != nil {
-			failure(w.err)
+			failure(err)
 		}
 		return
 	}
@@ -84,7 +209,7 @@ func (w Wrapper[T]) Match(success func(*T), failure func(error)) {
 // otherwise returns the original Wrapper unchanged.
 func (w Wrapper[T]) OrElse(defaultVal *T) Wrapper[T] {
 	if w.err != nil {
-		return Wrapper[T]{val: defaultVal, err: nil, errHandler: w.errHandler}
+		return Wrapper[T]{val: defaultVal, err: nil, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
 	}
 	return w
 }
@@ -93,37 +218,41 @@ func (w Wrapper[T]) OrElse(defaultVal *T) Wrapper[T] {
 // If the outer or inner wrapper has an error, it propagates that error.
 func Flatten[U any](w Wrapper[Wrapper[U]]) Wrapper[U] {
 	if w.err != nil {
-		return Wrapper[U]{err: w.err}
+		return Wrapper[U]{err: w.err, opts: w.opts, defers: w.defers, undo: w.undo}
 	}
 	inner := w.val
 	if inner.err != nil {
-		return Wrapper[U]{err: inner.err}
+		return Wrapper[U]{err: inner.err, opts: w.opts.merge(inner.opts), defers: inner.defers.then(w.defers), undo: inner.undo.then(w.undo)}
 	}
-	return Wrapper[U]{val: inner.val, err: nil, errHandler: w.errHandler}
+	return Wrapper[U]{val: inner.val, err: nil, errHandler: w.errHandler, opts: w.opts.merge(inner.opts), defers: inner.defers.then(w.defers), undo: inner.undo.then(w.undo)}
 }
 
 // Recover executes fn and recovers from any panic,
-// converting it into an error stored in the wrapper.
+// converting it into a *PanicError stored in the wrapper.
+// The error handler of the wrapper is preserved.
 // If the wrapper already has an error or if fn is nil, it does nothing.
 func (w Wrapper[T]) Recover(fn func() (*T, error)) (result Wrapper[T]) {
-	if w.err != nil || fn == nil {
+	if w.err != nil {
 		return w
 	}
+	if fn == nil {
+		return w.nilFunc()
+	}
 
 	defer func() {
 		if r := recover(); r != nil {
-			result = Wrapper[T]{err: fmt.Errorf("panic recovered: %v", r)}
+			result = Wrapper[T]{err: &PanicError{Value: r, Stack: debug.Stack()}, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
 		}
 	}()
 
 	val, err := fn()
-	result = Wrapper[T]{val: val, err: err, errHandler: w.errHandler}
+	result = guardNil(Wrapper[T]{val: val, err: err, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo})
 	return
 }
 
 // FilterWrappers returns a slice of Wrapper[T] where predicate is true and no error occurred.
 // If wrappers or predicate is nil, it returns the input slice unchanged.
-// Nil Wrapper pointers inside the slice are skipped.
+// Wrappers holding a nil value are skipped, as they would fail under WithNilGuard.
 func FilterWrappers[T any](wrappers []Wrapper[T], predicate func(*T) bool) []Wrapper[T] {
 	if wrappers == nil || predicate == nil {
 		return wrappers
@@ -133,7 +262,7 @@ func FilterWrappers[T any](wrappers []Wrapper[T], predicate func(*T) bool) []Wra
 
 	for _, w := range wrappers {
 		// Skip wrappers with error or nil value
-		if w.err != nil || w.val == nil {
+		if w.unusable() != nil {
 			continue
 		}
 		if predicate(w.val) {
@@ -146,7 +275,8 @@ func FilterWrappers[T any](wrappers []Wrapper[T], predicate func(*T) bool) []Wra
 
 // MapReduceWrappers maps each Wrapper's value using mapFn, then reduces the results using reduceFn.
 // Returns zero value if wrappers is empty or if mapFn or reduceFn is nil.
-// Skips Wrappers with errors or nil pointers in the slice.
+// Skips nil Wrappers, and Wrappers with errors or nil values, the same way FilterWrappers does.
+// Use MapReduceReport to find out which ones were skipped.
 func MapReduceWrappers[T any, R any](wrappers []*Wrapper[T], mapFn func(*T) R, reduceFn func(R, R) R, zero R) R {
 	if wrappers == nil || mapFn == nil || reduceFn == nil {
 		return zero
@@ -156,7 +286,7 @@ func MapReduceWrappers[T any, R any](wrappers []*Wrapper[T], mapFn func(*T) R, r
 	first := true
 
 	for _, w := range wrappers {
-		if w == nil || w.err != nil || w.val == nil {
+		if w == nil || w.unusable() != nil {
 			continue
 		}
 		mapped := mapFn(w.val)
@@ -174,24 +304,28 @@ func MapReduceWrappers[T any, R any](wrappers []*Wrapper[T], mapFn func(*T) R, r
 // Unwrap returns the wrapped value if no error occurred,
 // otherwise it panics with the error.
 // Similar to Rust's unwrap().
+// It runs the deferred actions first, a failing action makes it panic as well.
 func (w Wrapper[T]) Unwrap() *T {
-	if w.err != nil {
-		panic(fmt.Sprintf("called Unwrap on error: %v", w.err))
+	if err := w.finish(); err != nil {
+		panic(fmt.Sprintf("called Unwrap on error: %v", err))
 	}
 	return w.val
 }
 
 // IsSuccess returns true if the wrapper has no error.
+// It only inspects the wrapper: deferred actions do not run, and their errors are not reported.
 func (w Wrapper[T]) IsSuccess() bool {
 	return w.err == nil
 }
 
 // IsFailure returns true if the wrapper has an error.
+// It only inspects the wrapper: deferred actions do not run, and their errors are not reported.
 func (w Wrapper[T]) IsFailure() bool {
 	return w.err != nil
 }
 
 // HasError returns the error if any, otherwise nil.
+// It only inspects the wrapper: deferred actions do not run, and their errors are not reported.
 func (w Wrapper[T]) HasError() error {
 	return w.err
 }
@@ -199,40 +333,71 @@ func (w Wrapper[T]) HasError() error {
 // Bind applies a function that returns a Wrapper[U] to the current Wrapper's value if there is no error.
 // It returns a new Wrapper[U] with the result of applying the function.
 // If the current Wrapper has an error, Bind propagates it without calling the function.
-// If the function f is nil, Bind returns a zero-value Wrapper[U] with no error.
+// If the function f is nil, Bind returns a zero-value Wrapper[U] with no error, or ErrNilFunc in strict mode.
+// If the returned wrapper failed, the compensations registered by ThenCompensate run.
 func Bind[T any, U any](w *Wrapper[T], f func(*T) Wrapper[U]) Wrapper[U] {
 	if w.err != nil {
-		return Wrapper[U]{val: nil, err: w.err, errHandler: w.errHandler}
+		return Wrapper[U]{val: nil, err: w.err, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
 	}
 	if f == nil {
-		return Wrapper[U]{val: nil, err: nil, errHandler: w.errHandler}
+		if w.opts.strict {
+			return failAs[U](*w, ErrNilFunc)
+		}
+		return Wrapper[U]{errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
+	}
+	if w.opts.nilGuard && w.val == nil {
+		return failAs[U](*w, ErrNilValue)
+	}
+	next, err := protect(w.opts.panicSafe, Wrapper[U]{}, func() (Wrapper[U], error) {
+		return f(w.val), nil
+	})
+	if err != nil {
+		return failAs[U](*w, err)
+	}
+	next.opts = w.opts.merge(next.opts)
+	next.defers = next.defers.then(w.defers)
+	next.undo = next.undo.then(w.undo)
+	if next.err != nil {
+		next.err, next.undo = next.undo.compensate(next.err), nil
 	}
-	return f(w.val)
+	return guardNil(next)
 }
 
 // Apply applies a wrapped function (Wrapper of func(*T) (*U, error)) to the current Wrapper's value if there are no errors.
 // It returns a new Wrapper[U] containing the result of applying the function.
 // If either the current Wrapper or the function Wrapper has an error, Apply propagates the error and does not call the function.
-// If the function Wrapper's value is nil, returns a zero-value Wrapper[U].
+// If the function Wrapper holds no function, returns a zero-value Wrapper[U], or ErrNilFunc in strict mode.
 func Apply[T any, U any](w *Wrapper[T], f Wrapper[func(*T) (*U, error)]) Wrapper[U] {
 	if w.err != nil {
-		return Wrapper[U]{val: nil, err: w.err, errHandler: w.errHandler}
+		return Wrapper[U]{val: nil, err: w.err, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
 	}
 	if f.err != nil {
-		return Wrapper[U]{val: nil, err: f.err, errHandler: w.errHandler}
+		return Wrapper[U]{val: nil, err: f.err, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
 	}
-	if f.val == nil {
-		return Wrapper[U]{val: nil, err: nil, errHandler: w.errHandler}
+	if f.val == nil || *f.val == nil {
+		if w.opts.strict {
+			return failAs[U](*w, ErrNilFunc)
+		}
+		return Wrapper[U]{errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo}
+	}
+	if w.opts.nilGuard && w.val == nil {
+		return failAs[U](*w, ErrNilValue)
 	}
 
-	newVal, err := (*f.val)(w.val)
-	return Wrapper[U]{val: newVal, err: err, errHandler: w.errHandler}
+	newVal, err := protect(w.opts.panicSafe, nil, func() (*U, error) {
+		return (*f.val)(w.val)
+	})
+	if err != nil {
+		return failAs[U](*w, err)
+	}
+	return guardNil(Wrapper[U]{val: newVal, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo})
 }
 
 // Lift wraps a value into a Wrapper[T] using the provided error handler.
 // If the value is nil, it returns a Wrapper with a nil value and no error.
-func Lift[T any](v *T, errHandler func(error) error) *Wrapper[T] {
-	return &Wrapper[T]{val: v, err: nil, errHandler: errHandler}
+func Lift[T any](v *T, errHandler func(error) error, opts ...Option) *Wrapper[T] {
+	w := Wrapper[T]{val: v, err: nil, errHandler: errHandler, opts: newOptions(opts)}.record()
+	return &w
 }
 
 // LiftM lifts a pure function into the Wrapper monadic context.
@@ -240,30 +405,63 @@ func Lift[T any](v *T, errHandler func(error) error) *Wrapper[T] {
 // a Wrapper[T] into a Wrapper[U], applying f only if there is no error.
 //
 // If the input wrapper has an error, it returns a new Wrapper[U] with the same error.
-// If the function f is nil, it returns a zero value Wrapper[U] with no error.
+// If the function f is nil, it returns a zero value Wrapper[U] with no error, or ErrNilFunc in strict mode.
 func LiftM[T any, U any](f func(*T) *U) func(Wrapper[T]) Wrapper[U] {
 	return func(w Wrapper[T]) Wrapper[U] {
 		if w.err != nil {
-			return Wrapper[U]{err: w.err}
+			return Wrapper[U]{err: w.err, opts: w.opts, defers: w.defers, undo: w.undo}
 		}
 		if f == nil {
+			if w.opts.strict {
+				return failAs[U](w, ErrNilFunc)
+			}
 			var zeroU U
-			return Wrapper[U]{val: &zeroU, err: nil}
+			return Wrapper[U]{val: &zeroU, opts: w.opts, defers: w.defers, undo: w.undo}
 		}
-		res := f(w.val)
-		return Wrapper[U]{val: res, err: nil, errHandler: w.errHandler}
+		if w.opts.nilGuard && w.val == nil {
+			return failAs[U](w, ErrNilValue)
+		}
+		res, err := protect(w.opts.panicSafe, nil, func() (*U, error) {
+			return f(w.val), nil
+		})
+		if err != nil {
+			return failAs[U](w, err)
+		}
+		return guardNil(Wrapper[U]{val: res, errHandler: w.errHandler, opts: w.opts, defers: w.defers, undo: w.undo})
 	}
 }
 
+// FlatMapU chains w with a function returning a Wrapper of a different type.
+// If w has an error, it is propagated without calling f.
+// If f is nil, it returns a zero value Wrapper[U] with no error, or ErrNilFunc in strict mode.
+// If the returned wrapper failed, the compensations registered by ThenCompensate run.
 func FlatMapU[T any, U any](w Wrapper[T], f func(*T) Wrapper[U]) Wrapper[U] {
 	if w.err != nil {
-		return Wrapper[U]{err: w.err}
+		return Wrapper[U]{err: w.err, opts: w.opts, defers: w.defers, undo: w.undo}
 	}
 
 	if f == nil {
+		if w.opts.strict {
+			return failAs[U](w, ErrNilFunc)
+		}
 		var zeroU U
-		return Wrapper[U]{val: &zeroU, err: nil}
+		return Wrapper[U]{val: &zeroU, opts: w.opts, defers: w.defers, undo: w.undo}
+	}
+	if w.opts.nilGuard && w.val == nil {
+		return failAs[U](w, ErrNilValue)
 	}
 
-	return f(w.val)
+	next, err := protect(w.opts.panicSafe, Wrapper[U]{}, func() (Wrapper[U], error) {
+		return f(w.val), nil
+	})
+	if err != nil {
+		return failAs[U](w, err)
+	}
+	next.opts = w.opts.merge(next.opts)
+	next.defers = next.defers.then(w.defers)
+	next.undo = next.undo.then(w.undo)
+	if next.err != nil {
+		next.err, next.undo = next.undo.compensate(next.err), nil
+	}
+	return guardNil(next)
 }
@@ -522,3 +522,34 @@ func TestApply(t *testing.T) {
 		t.Fatal("expected zero-value Wrapper when func is nil")
 	}
 }
+
+func TestRecover_PanicError(t *testing.T) {
+	handler := func(e error) error { return e }
+	w := New(&MyStruct{Val: 1}, handler)
+
+	res := w.Recover(func() (*MyStruct, error) {
+		panic(42)
+	})
+	var pe *PanicError
+	if !errors.As(res.err, &pe) {
+		t.Fatalf("expected *PanicError, got %T", res.err)
+	}
+	if v, ok := pe.Value.(int); !ok || v != 42 {
+		t.Fatalf("expected panic value 42, got %v", pe.Value)
+	}
+	if len(pe.Stack) == 0 {
+		t.Fatal("expected stack trace to be captured")
+	}
+	if res.errHandler == nil {
+		t.Fatal("expected error handler to be preserved after panic")
+	}
+
+	// panic value that is an error can be unwrapped
+	errBoom := errors.New("boom")
+	res2 := w.Recover(func() (*MyStruct, error) {
+		panic(errBoom)
+	})
+	if !errors.Is(res2.err, errBoom) {
+		t.Fatalf("expected errors.Is to match panic error, got %v", res2.err)
+	}
+}
@@ -0,0 +1,79 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CompensationError is the error of a Wrapper whose failure ran the compensations registered by ThenCompensate.
+type CompensationError struct {
+	// Err is the failure that triggered the compensations.
+	Err error
+	// Undo holds the outcome of each compensation, in the order they ran. A nil entry means success.
+	Undo []error
+}
+
+func (e *CompensationError) Error() string {
+	failed := 0
+	for _, err := range e.Undo {
+		if err != nil {
+			failed++
+		}
+	}
+	return fmt.Sprintf("%v (compensated %d steps, %d failed)", e.Err, len(e.Undo), failed)
+}
+
+// Unwrap returns the failure that triggered the compensations.
+func (e *CompensationError) Unwrap() error {
+	return e.Err
+}
+
+// Compensated reports whether every compensation succeeded.
+func (e *CompensationError) Compensated() bool {
+	return errors.Join(e.Undo...) == nil
+}
+
+// ThenCompensate calls do like Then and, if it succeeds, registers undo for the value it returned.
+// If a later step of the wrapper fails and the error handler keeps the error, the registered undo
+// actions run in reverse order and the failure is reported as a *CompensationError.
+// A failure of do itself does not register undo, but runs the compensations of the earlier steps.
+// A nil undo makes it behave like Then.
+func (w Wrapper[T]) ThenCompensate(do func(*T) (*T, error), undo func(*T) error) Wrapper[T] {
+	if w.err != nil {
+		return w
+	}
+	if do == nil {
+		return w.nilFunc()
+	}
+
+	done := false
+	next := w.Then(func(v *T) (*T, error) {
+		res, err := do(v)
+		done = err == nil
+		return res, err
+	})
+	if !done || next.err != nil || undo == nil {
+		return next
+	}
+
+	val := next.val
+	next.undo = &cleanup{fn: func() error { return undo(val) }, next: next.undo}
+	return next
+}
+
+// compensate runs the actions of s in LIFO order and wraps err in a *CompensationError.
+// If s is empty, err is returned unchanged.
+func (s *cleanup) compensate(err error) error {
+	if s == nil {
+		return err
+	}
+
+	ce := &CompensationError{Err: err}
+	for n := s; n != nil; n = n.next {
+		n.once.Do(func() {
+			n.err = n.fn()
+		})
+		ce.Undo = append(ce.Undo, n.err)
+	}
+	return ce
+}
@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+type Ledger struct {
+	Entries []string
+}
+
+func addEntry(name string) func(*Ledger) (*Ledger, error) {
+	return func(l *Ledger) (*Ledger, error) {
+		l.Entries = append(l.Entries, name)
+		return l, nil
+	}
+}
+
+func removeEntry(name string, undone *[]string) func(*Ledger) error {
+	return func(l *Ledger) error {
+		*undone = append(*undone, name)
+		for i, e := range l.Entries {
+			if e == name {
+				l.Entries = append(l.Entries[:i], l.Entries[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+}
+
+func TestThenCompensate_UndoInReverseOrder(t *testing.T) {
+	var undone []string
+	errFail := errors.New("payment failed")
+
+	l, err := New(&Ledger{}, nil).
+		ThenCompensate(addEntry("order"), removeEntry("order", &undone)).
+		ThenCompensate(addEntry("stock"), removeEntry("stock", &undone)).
+		Then(func(*Ledger) (*Ledger, error) { return nil, errFail }).
+		Result()
+
+	var ce *CompensationError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected *CompensationError, got %v", err)
+	}
+	if !errors.Is(err, errFail) {
+		t.Fatalf("expected triggering error to be unwrappable, got %v", err)
+	}
+	if !ce.Compensated() || len(ce.Undo) != 2 {
+		t.Fatalf("expected 2 successful compensations, got %v", ce.Undo)
+	}
+	if len(undone) != 2 || undone[0] != "stock" || undone[1] != "order" {
+		t.Fatalf("expected undo order [stock order], got %v", undone)
+	}
+	if len(l.Entries) != 0 {
+		t.Fatalf("expected all entries to be undone, got %v", l.Entries)
+	}
+}
+
+func TestThenCompensate_FailedUndoReported(t *testing.T) {
+	errUndo := errors.New("undo failed")
+	_, err := New(&Ledger{}, nil).
+		ThenCompensate(addEntry("order"), func(*Ledger) error { return errUndo }).
+		Then(func(*Ledger) (*Ledger, error) { return nil, errors.New("fail") }).
+		Result()
+
+	var ce *CompensationError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected *CompensationError, got %v", err)
+	}
+	if ce.Compensated() || ce.Undo[0] != errUndo {
+		t.Fatalf("expected failed compensation to be reported, got %v", ce.Undo)
+	}
+}
+
+func TestThenCompensate_NoUndoOnSuccessOrSuppressedError(t *testing.T) {
+	undo := func(*Ledger) error {
+		t.Fatal("undo should not run")
+		return nil
+	}
+
+	l, err := New(&Ledger{}, nil).
+		ThenCompensate(addEntry("order"), undo).
+		Result()
+	if err != nil || len(l.Entries) != 1 {
+		t.Fatalf("expected one entry and no error, got %v, %v", l.Entries, err)
+	}
+
+	_, err = New(&Ledger{}, func(error) error { return nil }).
+		ThenCompensate(addEntry("order"), undo).
+		Then(func(*Ledger) (*Ledger, error) { return nil, errors.New("ignored") }).
+		Result()
+	if err != nil {
+		t.Fatalf("expected suppressed error, got %v", err)
+	}
+}
+
+func TestThenCompensate_FailingDoNotRegistered(t *testing.T) {
+	var undone []string
+	_, err := New(&Ledger{}, nil).
+		ThenCompensate(addEntry("order"), removeEntry("order", &undone)).
+		ThenCompensate(func(*Ledger) (*Ledger, error) {
+			return nil, errors.New("stock failed")
+		}, removeEntry("stock", &undone)).
+		Result()
+
+	var ce *CompensationError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected *CompensationError, got %v", err)
+	}
+	if len(undone) != 1 || undone[0] != "order" {
+		t.Fatalf("expected only the earlier step to be undone, got %v", undone)
+	}
+}
+
+func TestThenCompensate_TypeChangingSteps(t *testing.T) {
+	errFail := errors.New("shipping failed")
+	cases := map[string]func(Wrapper[Ledger]) Wrapper[int]{
+		"Bind failure": func(w Wrapper[Ledger]) Wrapper[int] {
+			return Bind(&w, func(*Ledger) Wrapper[int] { return *Lift(new(int), nil).WithError(errFail) })
+		},
+		"FlatMapU failure": func(w Wrapper[Ledger]) Wrapper[int] {
+			return FlatMapU(w, func(*Ledger) Wrapper[int] { return *Lift(new(int), nil).WithError(errFail) })
+		},
+		"Bind panic": func(w Wrapper[Ledger]) Wrapper[int] {
+			w.opts.panicSafe = true
+			return Bind(&w, func(*Ledger) Wrapper[int] { panic(errFail) })
+		},
+		"FlatMapU nil value": func(w Wrapper[Ledger]) Wrapper[int] {
+			w.opts.nilGuard = true
+			return FlatMapU(w, func(*Ledger) Wrapper[int] { return Wrapper[int]{} })
+		},
+	}
+	for name, step := range cases {
+		t.Run(name, func(t *testing.T) {
+			var undone []string
+			l := &Ledger{}
+			w := New(l, nil).ThenCompensate(addEntry("order"), removeEntry("order", &undone))
+
+			_, err := step(w).Result()
+			var ce *CompensationError
+			if !errors.As(err, &ce) || !ce.Compensated() {
+				t.Fatalf("expected a *CompensationError, got %v", err)
+			}
+			if len(undone) != 1 || len(l.Entries) != 0 {
+				t.Fatalf("expected the order to be undone, got %v, %v", undone, l.Entries)
+			}
+		})
+	}
+}
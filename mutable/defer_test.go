@@ -0,0 +1,120 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDefer_RunsInLIFOOrderOnResult(t *testing.T) {
+	var order []int
+	push := func(i int) func() error {
+		return func() error {
+			order = append(order, i)
+			return nil
+		}
+	}
+
+	c := New(&MyStruct{Val: 1}, nil).
+		Defer(push(1)).
+		Then((*MyStruct).Inc).
+		Defer(push(2)).
+		Then((*MyStruct).Double).
+		Defer(push(3))
+
+	if len(order) != 0 {
+		t.Fatal("expected deferred actions not to run before a terminal operation")
+	}
+
+	val, err := c.Result()
+	if err != nil || val.Val != 4 {
+		t.Fatalf("expected 4 and no error, got %d, %v", val.Val, err)
+	}
+	if len(order) != 3 || order[0] != 3 || order[1] != 2 || order[2] != 1 {
+		t.Fatalf("expected LIFO order [3 2 1], got %v", order)
+	}
+
+	// running a terminal operation again does not repeat the actions
+	c.Result()
+	if len(order) != 3 {
+		t.Fatalf("expected deferred actions to run once, got %v", order)
+	}
+}
+
+func TestDefer_ErrorsAggregated(t *testing.T) {
+	errA := errors.New("cleanup a")
+	errB := errors.New("cleanup b")
+
+	c := New(&MyStruct{Val: 2}, nil).
+		Defer(func() error { return errA }).
+		Defer(func() error { return errB }).
+		Then((*MyStruct).Inc).
+		Then((*MyStruct).FailIfThree).
+		Defer(func() error {
+			t.Fatal("expected Defer after a failure not to be registered")
+			return nil
+		})
+
+	var got error
+	c.Match(func(*MyStruct) {
+		t.Fatal("expected failure callback")
+	}, func(err error) {
+		got = err
+	})
+	if got == nil || !errors.Is(got, errA) || !errors.Is(got, errB) {
+		t.Fatalf("expected cleanup errors to be joined, got %v", got)
+	}
+	if _, err := c.Result(); !errors.Is(err, errA) {
+		t.Fatalf("expected remembered cleanup error on second terminal call, got %v", err)
+	}
+}
+
+func TestDefer_FailingCleanupFailsSuccessfulChain(t *testing.T) {
+	errClose := errors.New("close failed")
+	c := New(&MyStruct{Val: 1}, nil).Defer(func() error { return errClose })
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected Unwrap to panic on cleanup error")
+		}
+	}()
+	c.Unwrap()
+}
+
+func TestDefer_CarriedThroughFlatMapU(t *testing.T) {
+	var order []string
+	x := "x"
+	outer := New(&MyStruct{Val: 1}, nil).Defer(func() error {
+		order = append(order, "outer")
+		return nil
+	})
+
+	bound := FlatMapU(outer, func(*MyStruct) Wrapper[string] {
+		return New(&x, nil).Defer(func() error {
+			order = append(order, "inner")
+			return nil
+		})
+	})
+
+	if _, err := bound.Result(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(order) != 2 || order[0] != "inner" || order[1] != "outer" {
+		t.Fatalf("expected [inner outer], got %v", order)
+	}
+}
+
+func TestDefer_InspectionIsNotTerminal(t *testing.T) {
+	errClose := errors.New("close failed")
+	ran := 0
+	c := New(&MyStruct{Val: 1}, nil).Defer(func() error {
+		ran++
+		return errClose
+	})
+
+	if !c.IsSuccess() || c.IsFailure() || c.HasError() != nil || ran != 0 {
+		t.Fatalf("expected inspection not to run deferred actions, ran %d", ran)
+	}
+	if _, err := c.Result(); !errors.Is(err, errClose) || ran != 1 {
+		t.Fatalf("expected Result to run the deferred action, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package chain
+
+import "github.com/KeibiSoft/go-fp/internal/shared"
+
+// Sentinel errors reported in strict mode, see WithStrict.
+var (
+	// ErrNilFunc is reported when a step is given a nil function.
+	// It is the same value as immutable.ErrNilFunc.
+	ErrNilFunc = shared.ErrNilFunc
+	// ErrNilValue is reported when a step is given a nil value.
+	ErrNilValue = shared.ErrNilValue
+)
+
+// PanicError is the error stored in a Wrapper when a panic is recovered.
+// It keeps the original panic value and the stack trace of the panicking goroutine.
+// It is the same type as immutable.PanicError, so errors.As matches panics recovered by either package.
+type PanicError = shared.PanicError
@@ -0,0 +1,182 @@
+package chain
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrNoHistory is reported by Undo and Redo when there is no history entry to restore,
+	// or when the wrapper was created without WithHistory.
+	ErrNoHistory = errors.New("no history entry")
+	// ErrUnknownCheckpoint is reported by RestoreCheckpoint for a name that was never set
+	// or whose entry was dropped from the history.
+	ErrUnknownCheckpoint = errors.New("unknown checkpoint")
+)
+
+// history is a bounded list of value snapshots with a cursor on the current entry.
+// It is shared by every wrapper derived from the same New call.
+type history[T any] struct {
+	mu          sync.Mutex
+	cp          CopyFunc[T]
+	limit       int
+	entries     []*T
+	cursor      int
+	checkpoints map[string]int
+}
+
+// push records a snapshot of v after the cursor, discarding the redo entries.
+func (h *history[T]) push(v *T) error {
+	snap, err := h.cp(v)
+	if err != nil {
+		return &SnapshotError{Err: err}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.entries) > 0 {
+		h.entries = h.entries[:h.cursor+1]
+		for name, i := range h.checkpoints {
+			if i > h.cursor {
+				delete(h.checkpoints, name)
+			}
+		}
+	}
+	h.entries = append(h.entries, snap)
+
+	if h.limit > 0 && len(h.entries) > h.limit {
+		drop := len(h.entries) - h.limit
+		h.entries = append([]*T(nil), h.entries[drop:]...)
+		for name, i := range h.checkpoints {
+			if i < drop {
+				delete(h.checkpoints, name)
+			} else {
+				h.checkpoints[name] = i - drop
+			}
+		}
+	}
+	h.cursor = len(h.entries) - 1
+	return nil
+}
+
+// restore moves the cursor to i and copies that entry into dst in place.
+func (h *history[T]) restore(dst *T, i int) error {
+	snap, err := h.cp(h.entries[i])
+	if err != nil {
+		return &SnapshotError{Err: err}
+	}
+	*dst = *snap
+	h.cursor = i
+	return nil
+}
+
+// historyOf returns the history of w, or nil when WithHistory is not enabled for T.
+func (w Wrapper[T]) historyOf() *history[T] {
+	h, _ := w.opts.history.(*history[T])
+	return h
+}
+
+// record pushes the current value onto the history, if enabled.
+func (w Wrapper[T]) record() Wrapper[T] {
+	h := w.historyOf()
+	if h == nil || w.val == nil {
+		return w
+	}
+	if err := h.push(w.val); err != nil {
+		return w.fail(err)
+	}
+	return w
+}
+
+// move restores the entry at offset from the cursor, failing with ErrNoHistory if there is none.
+func (w Wrapper[T]) move(offset int) Wrapper[T] {
+	if w.err != nil {
+		return w
+	}
+	h := w.historyOf()
+	if h == nil || w.val == nil {
+		return w.fail(ErrNoHistory)
+	}
+
+	// The lock is released before failing, so the error handler may use the history.
+	h.mu.Lock()
+	err := ErrNoHistory
+	if i := h.cursor + offset; i >= 0 && i < len(h.entries) {
+		err = h.restore(w.val, i)
+	}
+	h.mu.Unlock()
+
+	if err != nil {
+		return w.fail(err)
+	}
+	return w
+}
+
+// Undo restores the value in place to the previous history entry.
+// If there is none, ErrNoHistory is passed to the error handler.
+func (w Wrapper[T]) Undo() Wrapper[T] {
+	return w.move(-1)
+}
+
+// Redo restores the value in place to the entry undone last.
+// If there is none, ErrNoHistory is passed to the error handler.
+func (w Wrapper[T]) Redo() Wrapper[T] {
+	return w.move(1)
+}
+
+// History returns the recorded snapshots, oldest first, including the entries that can be redone.
+// The snapshots are shared with the history and must not be modified.
+func (w Wrapper[T]) History() []*T {
+	h := w.historyOf()
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*T(nil), h.entries...)
+}
+
+// Checkpoint names the current history entry so it can be restored with RestoreCheckpoint.
+// Setting an existing name moves it. Without WithHistory it does nothing.
+func (w Wrapper[T]) Checkpoint(name string) Wrapper[T] {
+	if w.err != nil {
+		return w
+	}
+	h := w.historyOf()
+	if h == nil {
+		return w
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) > 0 {
+		h.checkpoints[name] = h.cursor
+	}
+	return w
+}
+
+// RestoreCheckpoint restores the value in place to the entry named by Checkpoint.
+// If the name is unknown, ErrUnknownCheckpoint is passed to the error handler.
+func (w Wrapper[T]) RestoreCheckpoint(name string) Wrapper[T] {
+	if w.err != nil {
+		return w
+	}
+	h := w.historyOf()
+	if h == nil || w.val == nil {
+		return w.fail(ErrUnknownCheckpoint)
+	}
+
+	h.mu.Lock()
+	err := ErrUnknownCheckpoint
+	if i, ok := h.checkpoints[name]; ok {
+		err = h.restore(w.val, i)
+	}
+	h.mu.Unlock()
+
+	if err != nil {
+		return w.fail(err)
+	}
+	return w
+}
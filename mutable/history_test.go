@@ -0,0 +1,129 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+type Doc struct {
+	Text string
+}
+
+func appendText(s string) func(*Doc) (*Doc, error) {
+	return func(d *Doc) (*Doc, error) {
+		d.Text += s
+		return d, nil
+	}
+}
+
+func TestHistory_UndoRedo(t *testing.T) {
+	doc := &Doc{}
+	w := New(doc, nil, WithHistory(ShallowCopy[Doc], 0)).
+		Then(appendText("a")).
+		Then(appendText("b"))
+
+	if got := len(w.History()); got != 3 {
+		t.Fatalf("expected 3 history entries, got %d", got)
+	}
+
+	w = w.Undo()
+	if doc.Text != "a" {
+		t.Fatalf("expected 'a' after undo, got %q", doc.Text)
+	}
+	w = w.Undo()
+	if doc.Text != "" {
+		t.Fatalf("expected '' after second undo, got %q", doc.Text)
+	}
+	w = w.Redo().Redo()
+	if doc.Text != "ab" {
+		t.Fatalf("expected 'ab' after redo, got %q", doc.Text)
+	}
+
+	if _, err := w.Redo().Result(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected ErrNoHistory when nothing to redo, got %v", err)
+	}
+}
+
+func TestHistory_NewStepDiscardsRedo(t *testing.T) {
+	doc := &Doc{}
+	w := New(doc, nil, WithHistory(ShallowCopy[Doc], 0)).
+		Then(appendText("a")).
+		Then(appendText("b")).
+		Undo().
+		Then(appendText("c"))
+
+	if doc.Text != "ac" {
+		t.Fatalf("expected 'ac', got %q", doc.Text)
+	}
+	if _, err := w.Redo().Result(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected redo entries to be discarded, got %v", err)
+	}
+}
+
+func TestHistory_Limit(t *testing.T) {
+	doc := &Doc{}
+	w := New(doc, nil, WithHistory(ShallowCopy[Doc], 2)).
+		Then(appendText("a")).
+		Then(appendText("b")).
+		Then(appendText("c"))
+
+	hist := w.History()
+	if len(hist) != 2 || hist[0].Text != "ab" || hist[1].Text != "abc" {
+		t.Fatalf("expected last 2 entries [ab abc], got %v", hist)
+	}
+	if _, err := w.Undo().Undo().Result(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected ErrNoHistory beyond limit, got %v", err)
+	}
+	if doc.Text != "ab" {
+		t.Fatalf("expected 'ab' after the only possible undo, got %q", doc.Text)
+	}
+}
+
+func TestHistory_Checkpoints(t *testing.T) {
+	doc := &Doc{}
+	w := New(doc, nil, WithHistory(GobCopy[Doc], 0)).
+		Then(appendText("a")).
+		Checkpoint("saved").
+		Then(appendText("b")).
+		Then(appendText("c"))
+
+	w = w.RestoreCheckpoint("saved")
+	if doc.Text != "a" {
+		t.Fatalf("expected 'a' at checkpoint, got %q", doc.Text)
+	}
+	w = w.Redo()
+	if doc.Text != "ab" {
+		t.Fatalf("expected 'ab' after redo from checkpoint, got %q", doc.Text)
+	}
+
+	if _, err := w.RestoreCheckpoint("missing").Result(); !errors.Is(err, ErrUnknownCheckpoint) {
+		t.Fatalf("expected ErrUnknownCheckpoint, got %v", err)
+	}
+}
+
+func TestHistory_ErrHandlerReadsHistory(t *testing.T) {
+	var w Wrapper[Doc]
+	seen := -1
+	w = New(&Doc{}, func(err error) error {
+		seen = len(w.History())
+		return err
+	}, WithHistory(ShallowCopy[Doc], 0)).Then(appendText("a"))
+
+	if _, err := w.Redo().Result(); !errors.Is(err, ErrNoHistory) || seen != 2 {
+		t.Fatalf("expected the handler to see 2 entries on a failed redo, got %d, %v", seen, err)
+	}
+	seen = -1
+	if _, err := w.RestoreCheckpoint("missing").Result(); !errors.Is(err, ErrUnknownCheckpoint) || seen != 2 {
+		t.Fatalf("expected the handler to see 2 entries on a failed restore, got %d, %v", seen, err)
+	}
+}
+
+func TestHistory_Disabled(t *testing.T) {
+	w := New(&Doc{}, nil).Then(appendText("a"))
+	if w.History() != nil {
+		t.Fatal("expected no history without WithHistory")
+	}
+	if _, err := w.Undo().Result(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected ErrNoHistory, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package chain
+
+import "runtime/debug"
+
+// Option configures a Wrapper created with New or Lift.
+// Options are carried along every step, including Bind, Apply, LiftM and FlatMapU.
+type Option func(*options)
+
+type options struct {
+	panicSafe bool
+	strict    bool
+	nilGuard  bool
+	// rollback snapshots the value before a step, see WithRollback.
+	rollback func(v any) (restore func(), err error)
+	// history holds the *history[T] created by WithHistory.
+	history any
+	audit   *auditor
+}
+
+// WithPanicSafe makes every step of the wrapper recover from panics raised by user functions,
+// storing them as a *PanicError instead of unwinding the caller.
+// Recovered panics are passed to the error handler like any other step error.
+func WithPanicSafe() Option {
+	return func(o *options) {
+		o.panicSafe = true
+	}
+}
+
+// WithStrict reports nil functions passed to steps as ErrNilFunc, instead of silently skipping them.
+// The error is passed to the error handler like any other step error.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithNilGuard fails the wrapper with ErrNilValue whenever a step would receive or produce a nil value.
+// The error is passed to the error handler like any other step error.
+func WithNilGuard() Option {
+	return func(o *options) {
+		o.nilGuard = true
+	}
+}
+
+// WithRollback snapshots the value with cp before every Then and Map step,
+// and restores it in place if the step fails or panics.
+// The option only applies to wrappers of T; it is ignored once Bind or FlatMapU change the type.
+func WithRollback[T any](cp CopyFunc[T]) Option {
+	return func(o *options) {
+		if cp == nil {
+			return
+		}
+		o.rollback = func(v any) (func(), error) {
+			p, ok := v.(*T)
+			if !ok {
+				return func() {}, nil
+			}
+			snap, err := cp(p)
+			if err != nil {
+				return nil, &SnapshotError{Err: err}
+			}
+			return func() { *p = *snap }, nil
+		}
+	}
+}
+
+// WithHistory keeps up to limit snapshots of the value, one per successful Then or Map step,
+// enabling Undo, Redo, History and named checkpoints. A limit of zero or less keeps every snapshot.
+// Snapshots are taken with cp; the option is ignored if cp is nil.
+// The history only applies to wrappers of T; it is ignored once Bind or FlatMapU change the type.
+func WithHistory[T any](cp CopyFunc[T], limit int) Option {
+	return func(o *options) {
+		if cp == nil {
+			return
+		}
+		o.history = &history[T]{cp: cp, limit: limit, checkpoints: map[string]int{}}
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// merge combines two option sets, enabling every mode set in either of them.
+func (o options) merge(other options) options {
+	o.panicSafe = o.panicSafe || other.panicSafe
+	o.strict = o.strict || other.strict
+	o.nilGuard = o.nilGuard || other.nilGuard
+	if o.rollback == nil {
+		o.rollback = other.rollback
+	}
+	if o.history == nil {
+		o.history = other.history
+	}
+	if o.audit == nil {
+		o.audit = other.audit
+	}
+	return o
+}
+
+// protect calls fn, converting a panic into a *PanicError when safe is set.
+// On panic the fallback value is returned alongside the error.
+func protect[V any](safe bool, fallback V, fn func() (V, error)) (val V, err error) {
+	if !safe {
+		return fn()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			val, err = fallback, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
@@ -0,0 +1,245 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPanicSafe_Then(t *testing.T) {
+	ms := &MyStruct{Val: 1}
+	var handled error
+	w := New(ms, func(e error) error {
+		handled = e
+		return e
+	}, WithPanicSafe()).
+		Then((*MyStruct).Inc). // 2
+		Then(func(*MyStruct) (*MyStruct, error) { panic("boom") }).
+		Then((*MyStruct).Inc) // skipped
+
+	val, err := w.Result()
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *PanicError, got %v", err)
+	}
+	if handled != err {
+		t.Fatal("expected panic to be passed to the error handler")
+	}
+	if val != ms || val.Val != 2 {
+		t.Fatalf("expected previous value to be kept, got %v", val)
+	}
+}
+
+func TestPanicSafe_HandlerSuppresses(t *testing.T) {
+	ms := &MyStruct{Val: 1}
+	val, err := New(ms, func(error) error { return nil }, WithPanicSafe()).
+		Map(func(*MyStruct) { panic("map") }).
+		Then((*MyStruct).Inc).
+		Result()
+
+	if err != nil {
+		t.Fatalf("expected error to be suppressed, got %v", err)
+	}
+	if val.Val != 2 {
+		t.Fatalf("expected value 2, got %d", val.Val)
+	}
+}
+
+func TestPanicSafe_FlatMapAndBind(t *testing.T) {
+	w := New(&MyStruct{Val: 1}, nil, WithPanicSafe())
+
+	fm := w.FlatMap(func(*MyStruct) Wrapper[MyStruct] { panic("flatmap") })
+	if _, ok := fm.err.(*PanicError); !ok {
+		t.Fatalf("expected *PanicError from FlatMap, got %v", fm.err)
+	}
+
+	bound := Bind(&w, func(*MyStruct) Wrapper[int] { panic("bind") })
+	if _, ok := bound.err.(*PanicError); !ok {
+		t.Fatalf("expected *PanicError from Bind, got %v", bound.err)
+	}
+
+	next := FlatMapU(w, func(m *MyStruct) Wrapper[int] {
+		return New(&m.Val, nil)
+	})
+	if !next.opts.panicSafe {
+		t.Fatal("expected panic-safe option to carry over through FlatMapU")
+	}
+}
+
+func TestPanicSafe_TypeChangingStepsUseHandler(t *testing.T) {
+	var handled []error
+	w := New(&MyStruct{Val: 1}, func(e error) error {
+		handled = append(handled, e)
+		return e
+	}, WithPanicSafe())
+
+	fn := func(*MyStruct) (*int, error) { panic("apply") }
+	results := []error{
+		Bind(&w, func(*MyStruct) Wrapper[int] { panic("bind") }).err,
+		FlatMapU(w, func(*MyStruct) Wrapper[int] { panic("flatmapu") }).err,
+		LiftM(func(*MyStruct) *int { panic("liftm") })(w).err,
+		Apply(&w, New(&fn, nil)).err,
+	}
+	if len(handled) != len(results) {
+		t.Fatalf("expected every panic to reach the error handler, got %v", handled)
+	}
+	for i, err := range results {
+		var pe *PanicError
+		if !errors.As(err, &pe) || err != handled[i] {
+			t.Fatalf("expected *PanicError from the handler, got %v", err)
+		}
+	}
+
+	suppressed := New(&MyStruct{Val: 1}, func(error) error { return nil }, WithPanicSafe())
+	if err := Bind(&suppressed, func(*MyStruct) Wrapper[int] { panic("bind") }).err; err != nil {
+		t.Fatalf("expected the handler to suppress the panic, got %v", err)
+	}
+}
+
+func BenchmarkThen(b *testing.B) {
+	w := New(&MyStruct{Val: 1}, nil)
+	for b.Loop() {
+		w.Then((*MyStruct).Inc).Then((*MyStruct).Double).Then((*MyStruct).Inc)
+	}
+}
+
+func BenchmarkThen_PanicSafe(b *testing.B) {
+	w := New(&MyStruct{Val: 1}, nil, WithPanicSafe())
+	for b.Loop() {
+		w.Then((*MyStruct).Inc).Then((*MyStruct).Double).Then((*MyStruct).Inc)
+	}
+}
+
+func TestStrict_NilFunctions(t *testing.T) {
+	var handled int
+	w := New(&MyStruct{Val: 1}, func(e error) error {
+		handled++
+		return e
+	}, WithStrict())
+
+	if _, err := w.Then(nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Then(nil), got %v", err)
+	}
+	if handled != 1 {
+		t.Fatalf("expected error handler to be called once, got %d", handled)
+	}
+	if _, err := w.Map(nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Map(nil), got %v", err)
+	}
+	if _, err := Bind[MyStruct, int](&w, nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Bind, got %v", err)
+	}
+	if _, err := LiftM[MyStruct, int](nil)(w).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from LiftM, got %v", err)
+	}
+	if _, err := FlatMapU[MyStruct, int](w, nil).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from FlatMapU, got %v", err)
+	}
+
+	if _, err := Apply(&w, Wrapper[func(*MyStruct) (*int, error)]{}).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Apply with nil function pointer, got %v", err)
+	}
+	var nilFn func(*MyStruct) (*int, error)
+	if _, err := Apply(&w, New(&nilFn, nil)).Result(); !errors.Is(err, ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc from Apply with nil function, got %v", err)
+	}
+	if handled != 7 {
+		t.Fatalf("expected every strict error to reach the error handler, got %d", handled)
+	}
+
+	// without strict mode nil functions are still skipped
+	if _, err := New(&MyStruct{Val: 1}, nil).Then(nil).Result(); err != nil {
+		t.Fatalf("expected no error without strict mode, got %v", err)
+	}
+	lax := New(&MyStruct{Val: 1}, nil)
+	if _, err := Apply(&lax, New(&nilFn, nil)).Result(); err != nil {
+		t.Fatalf("expected no error from Apply with nil function without strict mode, got %v", err)
+	}
+}
+
+func TestNilGuard_ThenReturnsNil(t *testing.T) {
+	ms := &MyStruct{Val: 1}
+	var handled error
+	w := New(ms, func(e error) error {
+		handled = e
+		return e
+	}, WithNilGuard()).
+		Then(func(*MyStruct) (*MyStruct, error) { return nil, nil }).
+		Then((*MyStruct).Inc) // skipped, would dereference nil
+
+	val, err := w.Result()
+	if !errors.Is(err, ErrNilValue) {
+		t.Fatalf("expected ErrNilValue, got %v", err)
+	}
+	if !errors.Is(handled, ErrNilValue) {
+		t.Fatal("expected ErrNilValue to be passed to the error handler")
+	}
+	if val != ms {
+		t.Fatal("expected previous value to be kept")
+	}
+}
+
+func TestNilGuard_InitialNilValue(t *testing.T) {
+	called := false
+	w := New[MyStruct](nil, nil, WithNilGuard()).
+		Map(func(*MyStruct) { called = true })
+	if called {
+		t.Fatal("expected Map not to be called with nil value")
+	}
+	if !errors.Is(w.err, ErrNilValue) {
+		t.Fatalf("expected ErrNilValue, got %v", w.err)
+	}
+
+	bound := FlatMapU(New[MyStruct](nil, nil, WithNilGuard()), func(*MyStruct) Wrapper[int] {
+		t.Fatal("expected FlatMapU not to call f with nil value")
+		return Wrapper[int]{}
+	})
+	if !errors.Is(bound.err, ErrNilValue) {
+		t.Fatalf("expected ErrNilValue from FlatMapU, got %v", bound.err)
+	}
+}
+
+func TestNilGuard_TypeChangingStepsUseHandler(t *testing.T) {
+	var handled []error
+	w := New[MyStruct](nil, func(e error) error {
+		handled = append(handled, e)
+		return e
+	}, WithNilGuard())
+
+	fn := func(*MyStruct) (*int, error) { return new(int), nil }
+	results := []error{
+		Bind(&w, func(*MyStruct) Wrapper[int] { return Wrapper[int]{} }).err,
+		FlatMapU(w, func(*MyStruct) Wrapper[int] { return Wrapper[int]{} }).err,
+		LiftM(func(*MyStruct) *int { return new(int) })(w).err,
+		Apply(&w, New(&fn, nil)).err,
+	}
+	if len(handled) != len(results) {
+		t.Fatalf("expected every nil value to reach the error handler, got %v", handled)
+	}
+	for _, err := range results {
+		if !errors.Is(err, ErrNilValue) {
+			t.Fatalf("expected ErrNilValue, got %v", err)
+		}
+	}
+}
+
+func TestThenNonNil(t *testing.T) {
+	w := New(&MyStruct{Val: 1}, nil).
+		ThenNonNil(func(*MyStruct) (*MyStruct, error) { return nil, nil })
+	if !errors.Is(w.err, ErrNilValue) {
+		t.Fatalf("expected ErrNilValue from ThenNonNil, got %v", w.err)
+	}
+
+	// without the guard Then keeps accepting nil values
+	w2 := New(&MyStruct{Val: 1}, nil).
+		Then(func(*MyStruct) (*MyStruct, error) { return nil, nil })
+	if w2.err != nil || w2.val != nil {
+		t.Fatalf("expected nil value and no error without nil guard, got %v, %v", w2.val, w2.err)
+	}
+
+	w3 := New[MyStruct](nil, nil).MapNonNil(func(*MyStruct) {
+		t.Fatal("expected MapNonNil not to call f with nil value")
+	})
+	if !errors.Is(w3.err, ErrNilValue) {
+		t.Fatalf("expected ErrNilValue from MapNonNil, got %v", w3.err)
+	}
+}
@@ -0,0 +1,66 @@
+package chain
+
+import "github.com/KeibiSoft/go-fp/internal/shared"
+
+// IndexedError is the error of a failed input, along with its index in the input slice.
+// It is the same type in the immutable and mutable packages.
+type IndexedError = shared.IndexedError
+
+// Report describes which inputs of a batch were used and which were skipped and why.
+// It is the same type in the immutable and mutable packages.
+type Report = shared.Report
+
+// PartitionWrappers splits wrappers into the values of the usable ones and the errors of the others.
+// A wrapper without error but with a nil value is reported with ErrNilValue.
+// Both keep the input order.
+func PartitionWrappers[T any](wrappers []Wrapper[T]) (values []*T, errs []IndexedError) {
+	for i, w := range wrappers {
+		if err := w.unusable(); err != nil {
+			errs = append(errs, IndexedError{Index: i, Err: err})
+			continue
+		}
+		values = append(values, w.val)
+	}
+	return values, errs
+}
+
+// MapReduceReport works like MapReduceWrappers, and also reports which wrappers were skipped and why.
+// Nil wrappers and wrappers with nil values are reported with ErrNilValue.
+// Returns zero and an empty report if mapFn or reduceFn is nil.
+func MapReduceReport[T any, R any](wrappers []*Wrapper[T], mapFn func(*T) R, reduceFn func(R, R) R, zero R) (R, Report) {
+	report := Report{Total: len(wrappers)}
+	if mapFn == nil || reduceFn == nil {
+		return zero, report
+	}
+
+	result := zero
+	for i, w := range wrappers {
+		err := ErrNilValue
+		if w != nil {
+			err = w.unusable()
+		}
+		if err != nil {
+			report.Skipped = append(report.Skipped, IndexedError{Index: i, Err: err})
+			continue
+		}
+		mapped := mapFn(w.val)
+		if report.Used == 0 {
+			result = mapped
+		} else {
+			result = reduceFn(result, mapped)
+		}
+		report.Used++
+	}
+	return result, report
+}
+
+// unusable returns why w cannot be used: its error, or ErrNilValue for a nil value.
+func (w Wrapper[T]) unusable() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.val == nil {
+		return ErrNilValue
+	}
+	return nil
+}
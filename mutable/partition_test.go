@@ -0,0 +1,59 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPartitionWrappers(t *testing.T) {
+	errA := errors.New("a failed")
+	one, three := 1, 3
+	wrappers := []Wrapper[int]{
+		{val: &one},
+		{err: errA},
+		{},
+		{val: &three},
+	}
+
+	values, errs := PartitionWrappers(wrappers)
+	if len(values) != 2 || *values[0] != 1 || *values[1] != 3 {
+		t.Fatalf("expected [1 3], got %v", values)
+	}
+	if len(errs) != 2 || errs[0].Index != 1 || !errors.Is(errs[0], errA) {
+		t.Fatalf("expected error at index 1, got %v", errs)
+	}
+	if errs[1].Index != 2 || !errors.Is(errs[1], ErrNilValue) {
+		t.Fatalf("expected ErrNilValue at index 2, got %v", errs[1])
+	}
+}
+
+func TestMapReduceReport(t *testing.T) {
+	errA := errors.New("a failed")
+	two, three := 2, 3
+	wrappers := []*Wrapper[int]{
+		{err: errA},
+		{val: &two},
+		nil,
+		{val: &three},
+	}
+
+	sum, report := MapReduceReport(wrappers, func(v *int) int { return *v * 10 }, func(a, b int) int { return a + b }, -1)
+	if sum != 50 {
+		t.Fatalf("expected 50, got %d", sum)
+	}
+	if report.Total != 4 || report.Used != 2 || len(report.Skipped) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if err := report.Err(); !errors.Is(err, errA) || !errors.Is(err, ErrNilValue) {
+		t.Fatalf("expected joined errors, got %v", err)
+	}
+}
+
+func TestMapReduceReport_AllFailed(t *testing.T) {
+	wrappers := []*Wrapper[int]{{err: errors.New("fail")}}
+
+	sum, report := MapReduceReport(wrappers, func(v *int) int { return *v }, func(a, b int) int { return a + b }, -1)
+	if sum != -1 || report.Used != 0 || report.Err() == nil {
+		t.Fatalf("expected zero and a failing report, got %d, %+v", sum, report)
+	}
+}
@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"errors"
+	"io"
+)
+
+// Bracket acquires a resource, passes it to use and releases it afterwards.
+// Release runs whether use succeeds, returns a failed Wrapper or panics; a panic is re-raised after release.
+// A release error is joined with the error of the Wrapper returned by use, bypassing the error handler.
+// If acquire fails, its error is returned and neither use nor release is called.
+// If acquire or use is nil, it returns a Wrapper with ErrNilFunc. A nil release is a no-op.
+func Bracket[R any, T any](acquire func() (R, error), use func(R) Wrapper[T], release func(R) error) (result Wrapper[T]) {
+	if acquire == nil || use == nil {
+		return Wrapper[T]{err: ErrNilFunc}
+	}
+
+	res, err := acquire()
+	if err != nil {
+		return Wrapper[T]{err: err}
+	}
+
+	if release != nil {
+		defer func() {
+			if rerr := release(res); rerr != nil {
+				result.err = errors.Join(result.err, rerr)
+			}
+		}()
+	}
+
+	return use(res)
+}
+
+// Using is Bracket for resources implementing io.Closer, closing them after use.
+func Using[C io.Closer, T any](acquire func() (C, error), use func(C) Wrapper[T]) Wrapper[T] {
+	return Bracket(acquire, use, func(c C) error {
+		return c.Close()
+	})
+}
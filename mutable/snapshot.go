@@ -0,0 +1,113 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/KeibiSoft/go-fp/internal/deep"
+	"github.com/KeibiSoft/go-fp/internal/shared"
+)
+
+// CopyFunc returns a deep copy of the value v points to.
+// It is the pluggable copy strategy used to snapshot a Wrapper value.
+type CopyFunc[T any] func(v *T) (*T, error)
+
+// Cloner is implemented by types that can deep-copy themselves, see CloneCopy.
+// It is the same interface as immutable.Cloner.
+type Cloner[T any] = shared.Cloner[T]
+
+// SnapshotError is returned when the value of a Wrapper cannot be copied.
+type SnapshotError struct {
+	Err error
+}
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("snapshot failed: %v", e.Err)
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// ShallowCopy copies the value v points to. Slices, maps and pointers inside T stay shared.
+func ShallowCopy[T any](v *T) (*T, error) {
+	cp := *v
+	return &cp, nil
+}
+
+// CloneCopy copies v using its Clone method. It fails if *T does not implement Cloner[T].
+func CloneCopy[T any](v *T) (*T, error) {
+	c, ok := any(v).(Cloner[T])
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement Cloner", v)
+	}
+	return c.Clone(), nil
+}
+
+// GobCopy deep-copies v by encoding and decoding it with encoding/gob.
+// Only exported fields are copied.
+func GobCopy[T any](v *T) (*T, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	cp := new(T)
+	if err := gob.NewDecoder(&buf).Decode(cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// DeepCopy deep-copies v using reflection, including unexported fields.
+// Functions and channels inside T stay shared.
+func DeepCopy[T any](v *T) (*T, error) {
+	return deep.Copy(v), nil
+}
+
+// snapshot copies the value when WithRollback is enabled.
+// The returned restore function writes the copy back in place; it is a no-op without rollback.
+func (w Wrapper[T]) snapshot() (restore func(), err error) {
+	if w.opts.rollback == nil || w.val == nil {
+		return func() {}, nil
+	}
+	return w.opts.rollback(w.val)
+}
+
+// Atomic runs steps on w as a group, restoring the value in place if the group fails or panics.
+// The value is snapshotted with cp before the group; a nil cp defaults to ShallowCopy.
+// If the snapshot fails, steps are not run and a *SnapshotError is passed to the error handler.
+func (w Wrapper[T]) Atomic(cp CopyFunc[T], steps func(Wrapper[T]) Wrapper[T]) Wrapper[T] {
+	if w.err != nil {
+		return w
+	}
+	if steps == nil {
+		return w.nilFunc()
+	}
+	if w.val == nil {
+		return steps(w)
+	}
+	if cp == nil {
+		cp = ShallowCopy[T]
+	}
+
+	snap, err := cp(w.val)
+	if err != nil {
+		return w.fail(&SnapshotError{Err: err})
+	}
+
+	orig := w.val
+	defer func() {
+		if r := recover(); r != nil {
+			*orig = *snap
+			panic(r)
+		}
+	}()
+
+	next := steps(w)
+	if next.err != nil {
+		*orig = *snap
+		next.val = orig
+	}
+	return next
+}
@@ -0,0 +1,105 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+type Account struct {
+	Balance int
+	Log     []string
+}
+
+func (a *Account) Clone() *Account {
+	return &Account{Balance: a.Balance, Log: append([]string(nil), a.Log...)}
+}
+
+func deposit(n int) func(*Account) (*Account, error) {
+	return func(a *Account) (*Account, error) {
+		a.Balance += n
+		a.Log = append(a.Log, "deposit")
+		return a, nil
+	}
+}
+
+func failHalfway(a *Account) (*Account, error) {
+	a.Balance = -1
+	return nil, errors.New("failed halfway")
+}
+
+func TestWithRollback_RestoresFailedStep(t *testing.T) {
+	acc := &Account{Balance: 10}
+	val, err := New(acc, nil, WithRollback(CloneCopy[Account])).
+		Then(deposit(5)).
+		Then(failHalfway).
+		Result()
+
+	if err == nil {
+		t.Fatal("expected error from failing step")
+	}
+	if val != acc {
+		t.Fatal("expected the original pointer to be kept")
+	}
+	if acc.Balance != 15 || len(acc.Log) != 1 {
+		t.Fatalf("expected state before failing step, got %+v", acc)
+	}
+}
+
+func TestWithRollback_MapPanic(t *testing.T) {
+	acc := &Account{Balance: 10}
+	_, err := New(acc, nil, WithPanicSafe(), WithRollback(GobCopy[Account])).
+		Map(func(a *Account) {
+			a.Balance = 0
+			panic("boom")
+		}).
+		Result()
+
+	if err == nil {
+		t.Fatal("expected panic to be stored as error")
+	}
+	if acc.Balance != 10 {
+		t.Fatalf("expected balance to be restored to 10, got %d", acc.Balance)
+	}
+}
+
+func TestWithRollback_SnapshotFails(t *testing.T) {
+	type noClone struct{ N int }
+	_, err := New(&noClone{}, nil, WithRollback(CloneCopy[noClone])).
+		Then(func(v *noClone) (*noClone, error) {
+			t.Fatal("step should not run when snapshot fails")
+			return v, nil
+		}).
+		Result()
+
+	var se *SnapshotError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected *SnapshotError, got %v", err)
+	}
+}
+
+func TestAtomic_RestoresGroup(t *testing.T) {
+	acc := &Account{Balance: 10}
+	_, err := New(acc, nil).
+		Then(deposit(1)).
+		Atomic(CloneCopy[Account], func(w Wrapper[Account]) Wrapper[Account] {
+			return w.Then(deposit(5)).Then(deposit(5)).Then(failHalfway)
+		}).
+		Result()
+
+	if err == nil {
+		t.Fatal("expected error from group")
+	}
+	if acc.Balance != 11 || len(acc.Log) != 1 {
+		t.Fatalf("expected state before the group, got %+v", acc)
+	}
+
+	acc2 := &Account{Balance: 10}
+	val, err := New(acc2, nil).
+		Atomic(nil, func(w Wrapper[Account]) Wrapper[Account] {
+			return w.Then(deposit(5))
+		}).
+		Result()
+	if err != nil || val.Balance != 15 {
+		t.Fatalf("expected successful group to keep changes, got %+v, %v", val, err)
+	}
+}
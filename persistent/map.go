@@ -0,0 +1,279 @@
+package persistent
+
+import (
+	"encoding/json"
+	"hash/maphash"
+	"iter"
+	"math/bits"
+)
+
+// seed is shared by every Map, so that versions of the same map agree on key hashes.
+var seed = maphash.MakeSeed()
+
+// Map is a persistent hash map, implemented as a hash array mapped trie (HAMT).
+// The zero value is an empty map ready to use.
+type Map[K comparable, V any] struct {
+	size int
+	root *hnode[K, V]
+}
+
+// hnode is a trie node. The bitmap marks which of the 32 slots are populated;
+// slots holds them in order, each either a child node or a leaf.
+type hnode[K comparable, V any] struct {
+	bitmap uint32
+	slots  []hslot[K, V]
+}
+
+type hslot[K comparable, V any] struct {
+	child *hnode[K, V]
+	leaf  *hleaf[K, V]
+}
+
+// hleaf holds the entries sharing one full hash; more than one entry means a collision.
+type hleaf[K comparable, V any] struct {
+	hash    uint64
+	entries []entry[K, V]
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// NewMap returns a map holding the entries of m.
+func NewMap[K comparable, V any](m map[K]V) Map[K, V] {
+	var pm Map[K, V]
+	for k, v := range m {
+		pm = pm.Set(k, v)
+	}
+	return pm
+}
+
+// Len returns the number of entries.
+func (m Map[K, V]) Len() int {
+	return m.size
+}
+
+func hashOf[K comparable](k K) uint64 {
+	return maphash.Comparable(seed, k)
+}
+
+func slotIndex(bitmap uint32, bit uint32) int {
+	return bits.OnesCount32(bitmap & (bit - 1))
+}
+
+// Get returns the value stored for k, and false if there is none.
+func (m Map[K, V]) Get(k K) (V, bool) {
+	h := hashOf(k)
+	n := m.root
+	for shift := uint(0); n != nil; shift += levelBits {
+		bit := uint32(1) << ((h >> shift) & mask)
+		if n.bitmap&bit == 0 {
+			break
+		}
+		s := n.slots[slotIndex(n.bitmap, bit)]
+		if s.child != nil {
+			n = s.child
+			continue
+		}
+		if s.leaf.hash == h {
+			for _, e := range s.leaf.entries {
+				if e.key == k {
+					return e.val, true
+				}
+			}
+		}
+		break
+	}
+	var zero V
+	return zero, false
+}
+
+// Has reports whether k is in the map.
+func (m Map[K, V]) Has(k K) bool {
+	_, ok := m.Get(k)
+	return ok
+}
+
+// Set returns a new map with k mapped to v.
+func (m Map[K, V]) Set(k K, v V) Map[K, V] {
+	root := m.root
+	if root == nil {
+		root = &hnode[K, V]{}
+	}
+	n, added := root.set(0, hashOf(k), k, v)
+	if added {
+		m.size++
+	}
+	m.root = n
+	return m
+}
+
+func (n *hnode[K, V]) with(i int, s hslot[K, V]) *hnode[K, V] {
+	slots := append([]hslot[K, V](nil), n.slots...)
+	slots[i] = s
+	return &hnode[K, V]{bitmap: n.bitmap, slots: slots}
+}
+
+func (n *hnode[K, V]) set(shift uint, h uint64, k K, v V) (*hnode[K, V], bool) {
+	bit := uint32(1) << ((h >> shift) & mask)
+	i := slotIndex(n.bitmap, bit)
+
+	if n.bitmap&bit == 0 {
+		slots := make([]hslot[K, V], 0, len(n.slots)+1)
+		slots = append(slots, n.slots[:i]...)
+		slots = append(slots, hslot[K, V]{leaf: &hleaf[K, V]{hash: h, entries: []entry[K, V]{{k, v}}}})
+		slots = append(slots, n.slots[i:]...)
+		return &hnode[K, V]{bitmap: n.bitmap | bit, slots: slots}, true
+	}
+
+	s := n.slots[i]
+	if s.child != nil {
+		child, added := s.child.set(shift+levelBits, h, k, v)
+		return n.with(i, hslot[K, V]{child: child}), added
+	}
+
+	if s.leaf.hash == h {
+		entries := append([]entry[K, V](nil), s.leaf.entries...)
+		for j, e := range entries {
+			if e.key == k {
+				entries[j].val = v
+				return n.with(i, hslot[K, V]{leaf: &hleaf[K, V]{hash: h, entries: entries}}), false
+			}
+		}
+		entries = append(entries, entry[K, V]{k, v})
+		return n.with(i, hslot[K, V]{leaf: &hleaf[K, V]{hash: h, entries: entries}}), true
+	}
+
+	// Two different hashes share this slot: push the existing leaf down one level.
+	child := &hnode[K, V]{
+		bitmap: uint32(1) << ((s.leaf.hash >> (shift + levelBits)) & mask),
+		slots:  []hslot[K, V]{s},
+	}
+	child, _ = child.set(shift+levelBits, h, k, v)
+	return n.with(i, hslot[K, V]{child: child}), true
+}
+
+// Delete returns a new map without k. If k is not present, m is returned unchanged.
+func (m Map[K, V]) Delete(k K) Map[K, V] {
+	if m.root == nil {
+		return m
+	}
+	n, removed := m.root.delete(0, hashOf(k), k)
+	if !removed {
+		return m
+	}
+	m.size--
+	m.root = n
+	return m
+}
+
+func (n *hnode[K, V]) delete(shift uint, h uint64, k K) (*hnode[K, V], bool) {
+	bit := uint32(1) << ((h >> shift) & mask)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	i := slotIndex(n.bitmap, bit)
+	s := n.slots[i]
+
+	if s.child != nil {
+		child, removed := s.child.delete(shift+levelBits, h, k)
+		if !removed {
+			return n, false
+		}
+		switch {
+		case len(child.slots) == 0:
+			return n.without(i, bit), true
+		case len(child.slots) == 1 && child.slots[0].leaf != nil:
+			// Collapse a child holding a single leaf back into this node.
+			return n.with(i, child.slots[0]), true
+		}
+		return n.with(i, hslot[K, V]{child: child}), true
+	}
+
+	if s.leaf.hash != h {
+		return n, false
+	}
+	for j, e := range s.leaf.entries {
+		if e.key != k {
+			continue
+		}
+		if len(s.leaf.entries) == 1 {
+			return n.without(i, bit), true
+		}
+		entries := make([]entry[K, V], 0, len(s.leaf.entries)-1)
+		entries = append(entries, s.leaf.entries[:j]...)
+		entries = append(entries, s.leaf.entries[j+1:]...)
+		return n.with(i, hslot[K, V]{leaf: &hleaf[K, V]{hash: h, entries: entries}}), true
+	}
+	return n, false
+}
+
+func (n *hnode[K, V]) without(i int, bit uint32) *hnode[K, V] {
+	slots := make([]hslot[K, V], 0, len(n.slots)-1)
+	slots = append(slots, n.slots[:i]...)
+	slots = append(slots, n.slots[i+1:]...)
+	return &hnode[K, V]{bitmap: n.bitmap &^ bit, slots: slots}
+}
+
+// All returns an iterator over the entries. The order is unspecified but stable for a given map.
+func (m Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.root.each(yield)
+	}
+}
+
+func (n *hnode[K, V]) each(yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	for _, s := range n.slots {
+		if s.child != nil {
+			if !s.child.each(yield) {
+				return false
+			}
+			continue
+		}
+		for _, e := range s.leaf.entries {
+			if !yield(e.key, e.val) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Keys returns an iterator over the keys.
+func (m Map[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ToMap returns the entries as a new Go map.
+func (m Map[K, V]) ToMap() map[K]V {
+	out := make(map[K]V, m.size)
+	for k, v := range m.All() {
+		out[k] = v
+	}
+	return out
+}
+
+// MarshalJSON encodes the map as a JSON object, following the rules of encoding/json for Go maps.
+func (m Map[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+// UnmarshalJSON decodes a JSON object into the map, replacing its contents.
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	var gm map[K]V
+	if err := json.Unmarshal(data, &gm); err != nil {
+		return err
+	}
+	*m = NewMap(gm)
+	return nil
+}
@@ -0,0 +1,92 @@
+package persistent
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMap_SetGetDelete(t *testing.T) {
+	var m Map[int, int]
+	for i := range 5000 {
+		m = m.Set(i, i*i)
+	}
+	if m.Len() != 5000 {
+		t.Fatalf("expected 5000 entries, got %d", m.Len())
+	}
+	for i := range 5000 {
+		if v, ok := m.Get(i); !ok || v != i*i {
+			t.Fatalf("expected %d for key %d, got %d, %v", i*i, i, v, ok)
+		}
+	}
+
+	m2 := m
+	for i := 0; i < 5000; i += 2 {
+		m2 = m2.Delete(i)
+	}
+	if m2.Len() != 2500 {
+		t.Fatalf("expected 2500 entries after delete, got %d", m2.Len())
+	}
+	for i := range 5000 {
+		_, ok := m2.Get(i)
+		if ok != (i%2 == 1) {
+			t.Fatalf("unexpected presence of key %d: %v", i, ok)
+		}
+	}
+	if m.Len() != 5000 || !m.Has(0) {
+		t.Fatal("expected original map to be unchanged")
+	}
+
+	if m2.Delete(0).Len() != 2500 {
+		t.Fatal("expected deleting a missing key to be a no-op")
+	}
+}
+
+func TestMap_Overwrite(t *testing.T) {
+	m := NewMap(map[string]int{"a": 1})
+	m2 := m.Set("a", 2)
+	if m2.Len() != 1 {
+		t.Fatalf("expected overwrite to keep length 1, got %d", m2.Len())
+	}
+	if v, _ := m.Get("a"); v != 1 {
+		t.Fatal("expected old version to keep its value")
+	}
+	if v, _ := m2.Get("a"); v != 2 {
+		t.Fatal("expected new version to hold the new value")
+	}
+}
+
+func TestMap_IterAndJSON(t *testing.T) {
+	m := NewMap(map[string]int{"a": 1, "b": 2, "c": 3})
+
+	sum := 0
+	for _, v := range m.All() {
+		sum += v
+	}
+	if sum != 6 {
+		t.Fatalf("expected sum 6, got %d", sum)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"a":1,"b":2,"c":3}` {
+		t.Fatalf("unexpected JSON %s, %v", data, err)
+	}
+	var back Map[string, int]
+	if err := json.Unmarshal(data, &back); err != nil || back.Len() != 3 {
+		t.Fatalf("unexpected decoded map %v, %v", back.ToMap(), err)
+	}
+}
+
+func TestMap_Collision(t *testing.T) {
+	root := &hnode[string, int]{}
+	root, _ = root.set(0, 42, "a", 1)
+	root, _ = root.set(0, 42, "b", 2)
+	m := Map[string, int]{size: 2, root: root}
+
+	if len(m.ToMap()) != 2 {
+		t.Fatalf("expected both colliding entries, got %v", m.ToMap())
+	}
+	root2, removed := root.delete(0, 42, "a")
+	if !removed || len(root2.slots[0].leaf.entries) != 1 || root2.slots[0].leaf.entries[0].key != "b" {
+		t.Fatal("expected delete to remove only the matching colliding entry")
+	}
+}
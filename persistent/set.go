@@ -0,0 +1,73 @@
+package persistent
+
+import (
+	"encoding/json"
+	"iter"
+)
+
+// Set is a persistent set, backed by a Map.
+// The zero value is an empty set ready to use.
+type Set[T comparable] struct {
+	m Map[T, struct{}]
+}
+
+// NewSet returns a set holding values.
+func NewSet[T comparable](values ...T) Set[T] {
+	var s Set[T]
+	for _, v := range values {
+		s = s.Add(v)
+	}
+	return s
+}
+
+// Len returns the number of elements.
+func (s Set[T]) Len() int {
+	return s.m.Len()
+}
+
+// Has reports whether v is in the set.
+func (s Set[T]) Has(v T) bool {
+	return s.m.Has(v)
+}
+
+// Add returns a new set with v added.
+func (s Set[T]) Add(v T) Set[T] {
+	if s.m.Has(v) {
+		return s
+	}
+	return Set[T]{m: s.m.Set(v, struct{}{})}
+}
+
+// Delete returns a new set without v.
+func (s Set[T]) Delete(v T) Set[T] {
+	return Set[T]{m: s.m.Delete(v)}
+}
+
+// All returns an iterator over the elements. The order is unspecified but stable for a given set.
+func (s Set[T]) All() iter.Seq[T] {
+	return s.m.Keys()
+}
+
+// Slice returns the elements as a new slice, in the order of All.
+func (s Set[T]) Slice() []T {
+	out := make([]T, 0, s.Len())
+	for v := range s.All() {
+		out = append(out, v)
+	}
+	return out
+}
+
+// MarshalJSON encodes the set as a JSON array.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Slice())
+}
+
+// UnmarshalJSON decodes a JSON array into the set, replacing its contents.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*s = NewSet(values...)
+	return nil
+}
@@ -0,0 +1,30 @@
+package persistent
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet(1, 2, 3, 2)
+	if s.Len() != 3 {
+		t.Fatalf("expected 3 elements, got %d", s.Len())
+	}
+
+	s2 := s.Add(4).Delete(1)
+	if !s2.Has(4) || s2.Has(1) || s2.Len() != 3 {
+		t.Fatalf("unexpected new version %v", s2.Slice())
+	}
+	if !s.Has(1) || s.Has(4) {
+		t.Fatal("expected old version to be unchanged")
+	}
+
+	data, err := json.Marshal(NewSet("x"))
+	if err != nil || string(data) != `["x"]` {
+		t.Fatalf("unexpected JSON %s, %v", data, err)
+	}
+	var back Set[string]
+	if err := json.Unmarshal([]byte(`["a","b","a"]`), &back); err != nil || back.Len() != 2 {
+		t.Fatalf("unexpected decoded set %v, %v", back.Slice(), err)
+	}
+}
@@ -0,0 +1,257 @@
+// Package persistent provides immutable collections with structural sharing.
+// Every update returns a new version in O(log32 n) and leaves the previous one untouched,
+// so they can be carried through an immutable.Chain without copying.
+package persistent
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+)
+
+const (
+	levelBits = 5
+	width     = 1 << levelBits
+	mask      = width - 1
+)
+
+// Vector is a persistent indexed sequence, implemented as a 32-way trie with a tail buffer.
+// The zero value is an empty vector ready to use.
+type Vector[T any] struct {
+	size  int
+	shift uint
+	root  *vnode[T]
+	tail  []T
+}
+
+type vnode[T any] struct {
+	children []*vnode[T]
+	values   []T
+}
+
+// NewVector returns a vector holding values.
+func NewVector[T any](values ...T) Vector[T] {
+	var v Vector[T]
+	for _, x := range values {
+		v = v.Append(x)
+	}
+	return v
+}
+
+// Len returns the number of elements.
+func (v Vector[T]) Len() int {
+	return v.size
+}
+
+func (v Vector[T]) tailOffset() int {
+	if v.size < width {
+		return 0
+	}
+	return ((v.size - 1) >> levelBits) << levelBits
+}
+
+// leaf returns the values of the leaf holding index i.
+func (v Vector[T]) leaf(i int) []T {
+	if i >= v.tailOffset() {
+		return v.tail
+	}
+	n := v.root
+	for level := v.shift; level > 0; level -= levelBits {
+		n = n.children[(i>>level)&mask]
+	}
+	return n.values
+}
+
+// Get returns the element at index i, and false if i is out of range.
+func (v Vector[T]) Get(i int) (T, bool) {
+	if i < 0 || i >= v.size {
+		var zero T
+		return zero, false
+	}
+	return v.leaf(i)[i&mask], true
+}
+
+// At returns the element at index i. It panics if i is out of range.
+func (v Vector[T]) At(i int) T {
+	if i < 0 || i >= v.size {
+		panic(fmt.Sprintf("persistent: index %d out of range [0:%d]", i, v.size))
+	}
+	return v.leaf(i)[i&mask]
+}
+
+// Append returns a new vector with x added at the end.
+func (v Vector[T]) Append(x T) Vector[T] {
+	if v.root == nil {
+		v.root, v.shift = &vnode[T]{}, levelBits
+	}
+
+	if v.size-v.tailOffset() < width {
+		tail := make([]T, len(v.tail)+1)
+		copy(tail, v.tail)
+		tail[len(v.tail)] = x
+		return Vector[T]{size: v.size + 1, shift: v.shift, root: v.root, tail: tail}
+	}
+
+	full := &vnode[T]{values: v.tail}
+	root, shift := v.root, v.shift
+	if (v.size >> levelBits) > (1 << v.shift) {
+		root = &vnode[T]{children: []*vnode[T]{v.root, newPath(v.shift, full)}}
+		shift += levelBits
+	} else {
+		root = v.pushTail(v.shift, v.root, full)
+	}
+	return Vector[T]{size: v.size + 1, shift: shift, root: root, tail: []T{x}}
+}
+
+func newPath[T any](level uint, n *vnode[T]) *vnode[T] {
+	if level == 0 {
+		return n
+	}
+	return &vnode[T]{children: []*vnode[T]{newPath(level-levelBits, n)}}
+}
+
+func (v Vector[T]) pushTail(level uint, parent, full *vnode[T]) *vnode[T] {
+	sub := ((v.size - 1) >> level) & mask
+	ret := &vnode[T]{children: append([]*vnode[T](nil), parent.children...)}
+
+	var child *vnode[T]
+	switch {
+	case level == levelBits:
+		child = full
+	case sub < len(parent.children):
+		child = v.pushTail(level-levelBits, parent.children[sub], full)
+	default:
+		child = newPath(level-levelBits, full)
+	}
+
+	if sub < len(ret.children) {
+		ret.children[sub] = child
+	} else {
+		ret.children = append(ret.children, child)
+	}
+	return ret
+}
+
+// Set returns a new vector with the element at index i replaced by x.
+// It panics if i is out of range.
+func (v Vector[T]) Set(i int, x T) Vector[T] {
+	if i < 0 || i >= v.size {
+		panic(fmt.Sprintf("persistent: index %d out of range [0:%d]", i, v.size))
+	}
+
+	if i >= v.tailOffset() {
+		tail := append([]T(nil), v.tail...)
+		tail[i&mask] = x
+		v.tail = tail
+		return v
+	}
+	v.root = assoc(v.shift, v.root, i, x)
+	return v
+}
+
+func assoc[T any](level uint, n *vnode[T], i int, x T) *vnode[T] {
+	if level == 0 {
+		values := append([]T(nil), n.values...)
+		values[i&mask] = x
+		return &vnode[T]{values: values}
+	}
+	sub := (i >> level) & mask
+	ret := &vnode[T]{children: append([]*vnode[T](nil), n.children...)}
+	ret.children[sub] = assoc(level-levelBits, n.children[sub], i, x)
+	return ret
+}
+
+// Pop returns a new vector without its last element. Popping an empty vector returns it unchanged.
+func (v Vector[T]) Pop() Vector[T] {
+	switch {
+	case v.size == 0:
+		return v
+	case v.size == 1:
+		return Vector[T]{}
+	case v.size-v.tailOffset() > 1:
+		v.tail = v.tail[: len(v.tail)-1 : len(v.tail)-1]
+		v.size--
+		return v
+	}
+
+	tail := v.leaf(v.size - 2)
+	root := v.popTail(v.shift, v.root)
+	shift := v.shift
+	if root == nil {
+		root = &vnode[T]{}
+	}
+	if shift > levelBits && len(root.children) == 1 {
+		root = root.children[0]
+		shift -= levelBits
+	}
+	return Vector[T]{size: v.size - 1, shift: shift, root: root, tail: tail}
+}
+
+func (v Vector[T]) popTail(level uint, n *vnode[T]) *vnode[T] {
+	sub := ((v.size - 2) >> level) & mask
+	if level > levelBits {
+		child := v.popTail(level-levelBits, n.children[sub])
+		if child == nil && sub == 0 {
+			return nil
+		}
+		ret := &vnode[T]{children: append([]*vnode[T](nil), n.children[:sub+1]...)}
+		if child == nil {
+			ret.children = ret.children[:sub]
+		} else {
+			ret.children[sub] = child
+		}
+		return ret
+	}
+	if sub == 0 {
+		return nil
+	}
+	return &vnode[T]{children: append([]*vnode[T](nil), n.children[:sub]...)}
+}
+
+// All returns an iterator over the indexes and elements, in order.
+func (v Vector[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < v.size; i += width {
+			for j, x := range v.leaf(i) {
+				if !yield(i+j, x) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the elements, in order.
+func (v Vector[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, x := range v.All() {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// Slice returns the elements as a new slice.
+func (v Vector[T]) Slice() []T {
+	s := make([]T, 0, v.size)
+	for x := range v.Values() {
+		s = append(s, x)
+	}
+	return s
+}
+
+// MarshalJSON encodes the vector as a JSON array.
+func (v Vector[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Slice())
+}
+
+// UnmarshalJSON decodes a JSON array into the vector, replacing its contents.
+func (v *Vector[T]) UnmarshalJSON(data []byte) error {
+	var s []T
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*v = NewVector(s...)
+	return nil
+}
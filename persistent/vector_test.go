@@ -0,0 +1,120 @@
+package persistent
+
+import (
+	"encoding/json"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+func TestVector_AppendGet(t *testing.T) {
+	var v Vector[int]
+	versions := []Vector[int]{v}
+	for i := range 2000 {
+		v = v.Append(i)
+		versions = append(versions, v)
+	}
+
+	if v.Len() != 2000 {
+		t.Fatalf("expected length 2000, got %d", v.Len())
+	}
+	for i := range 2000 {
+		if got := v.At(i); got != i {
+			t.Fatalf("expected %d at index %d, got %d", i, i, got)
+		}
+	}
+	// older versions are untouched
+	if versions[100].Len() != 100 || versions[100].At(99) != 99 {
+		t.Fatal("expected older version to be unchanged")
+	}
+	if _, ok := v.Get(2000); ok {
+		t.Fatal("expected Get out of range to report false")
+	}
+}
+
+func TestVector_Set(t *testing.T) {
+	v := NewVector[int]()
+	for i := range 100 {
+		v = v.Append(i)
+	}
+
+	v2 := v.Set(3, -3).Set(99, -99)
+	if v2.At(3) != -3 || v2.At(99) != -99 {
+		t.Fatal("expected Set to update the new version")
+	}
+	if v.At(3) != 3 || v.At(99) != 99 {
+		t.Fatal("expected Set to leave the old version untouched")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Set out of range to panic")
+		}
+	}()
+	v.Set(100, 0)
+}
+
+func TestVector_Pop(t *testing.T) {
+	var v Vector[int]
+	for i := range 1100 {
+		v = v.Append(i)
+	}
+
+	p := v
+	for n := 1100; n > 0; n-- {
+		if p.Len() != n || p.At(n-1) != n-1 {
+			t.Fatalf("expected last element %d at length %d, got %d", n-1, p.Len(), p.At(p.Len()-1))
+		}
+		p = p.Pop()
+	}
+	if p.Len() != 0 || p.Pop().Len() != 0 {
+		t.Fatal("expected empty vector after popping everything")
+	}
+	if v.Len() != 1100 || v.At(1099) != 1099 {
+		t.Fatal("expected original vector to be unchanged")
+	}
+
+	// the vector is still usable after shrinking
+	p = v.Pop().Pop().Append(7)
+	if p.Len() != 1099 || p.At(1098) != 7 || p.At(500) != 500 {
+		t.Fatal("unexpected contents after pop and append")
+	}
+}
+
+func TestVector_IterAndJSON(t *testing.T) {
+	v := NewVector("a", "b", "c")
+
+	var got []string
+	for i, s := range v.All() {
+		if i == 2 {
+			break
+		}
+		got = append(got, s)
+	}
+	if len(got) != 2 || got[1] != "b" {
+		t.Fatalf("expected early-terminated iteration [a b], got %v", got)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil || string(data) != `["a","b","c"]` {
+		t.Fatalf("unexpected JSON %s, %v", data, err)
+	}
+	var back Vector[string]
+	if err := json.Unmarshal(data, &back); err != nil || back.Len() != 3 || back.At(2) != "c" {
+		t.Fatalf("unexpected decoded vector %v, %v", back.Slice(), err)
+	}
+}
+
+func TestVector_InChain(t *testing.T) {
+	start := NewVector(1, 2)
+	res, err := immutable.Wrap(start).
+		Map(func(v Vector[int]) Vector[int] { return v.Append(3) }).
+		Map(func(v Vector[int]) Vector[int] { return v.Set(0, 10) }).
+		Result()
+	if err != nil || res.Len() != 3 || res.At(0) != 10 {
+		t.Fatalf("unexpected chain result %v, %v", res.Slice(), err)
+	}
+	if start.Len() != 2 || start.At(0) != 1 {
+		t.Fatal("expected the initial version to stay untouched")
+	}
+}
@@ -0,0 +1,242 @@
+// Package pipeline assembles immutable.Chain pipelines from JSON definitions.
+//
+// Step functions are registered by name, and a definition lists the steps to run in order,
+// with their parameters, whether they are enabled, and their retry and timeout settings.
+// Steps can then be reordered or toggled by editing the definition, without recompiling.
+// Load validates the definition against the registry, so unknown steps, invalid parameters
+// and steps registered for another type are reported before anything runs.
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+// Definition is the JSON description of a pipeline.
+type Definition struct {
+	Name  string    `json:"name"`
+	Steps []StepDef `json:"steps"`
+}
+
+// StepDef is the JSON description of a pipeline step.
+type StepDef struct {
+	Step   string          `json:"step"`
+	Params json.RawMessage `json:"params,omitempty"`
+	// Enabled defaults to true; disabled steps are validated but not run.
+	Enabled *bool `json:"enabled,omitempty"`
+	Retry   Retry `json:"retry,omitzero"`
+	// Timeout bounds each attempt through the context given to the step function.
+	Timeout Duration `json:"timeout,omitzero"`
+}
+
+// Retry configures how many times a failing step is attempted, and the pause between attempts.
+// Zero attempts means one.
+type Retry struct {
+	Attempts int      `json:"attempts,omitempty"`
+	Backoff  Duration `json:"backoff,omitzero"`
+}
+
+// Duration is a time.Duration written in JSON as a string such as "1.5s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// TypeMismatchError is reported by Load for a step registered for another type than the pipeline.
+type TypeMismatchError struct {
+	Step string
+	Want reflect.Type
+	Got  reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("step %q operates on %v, pipeline on %v", e.Step, e.Got, e.Want)
+}
+
+// StepError is the error of a pipeline step, after its last attempt.
+type StepError struct {
+	Step     string
+	Attempts int
+	Err      error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %q failed after %d attempt(s): %v", e.Step, e.Attempts, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// LoadOption configures Load.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	registry *Registry
+}
+
+// WithRegistry makes Load resolve step names in r instead of the default registry.
+func WithRegistry(r *Registry) LoadOption {
+	return func(o *loadOptions) {
+		if r != nil {
+			o.registry = r
+		}
+	}
+}
+
+type step[T any] struct {
+	name    string
+	fn      StepFunc[T]
+	retry   Retry
+	timeout time.Duration
+}
+
+// Pipeline is a validated sequence of steps operating on T.
+type Pipeline[T any] struct {
+	name  string
+	steps []step[T]
+}
+
+// Load parses a JSON Definition and resolves its steps, reporting every invalid step at once.
+func Load[T any](data []byte, opts ...LoadOption) (*Pipeline[T], error) {
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("invalid pipeline definition: %w", err)
+	}
+	return FromDefinition[T](def, opts...)
+}
+
+// FromDefinition resolves the steps of def, like Load.
+func FromDefinition[T any](def Definition, opts ...LoadOption) (*Pipeline[T], error) {
+	o := loadOptions{registry: Default}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	p := &Pipeline[T]{name: def.Name}
+	want := reflect.TypeFor[T]()
+	var errs []error
+	for i, sd := range def.Steps {
+		fail := func(err error) {
+			errs = append(errs, fmt.Errorf("step %d (%q): %w", i, sd.Step, err))
+		}
+		e, ok := o.registry.lookup(sd.Step)
+		if !ok {
+			fail(ErrUnknownStep)
+			continue
+		}
+		if e.typ != want {
+			fail(&TypeMismatchError{Step: sd.Step, Want: want, Got: e.typ})
+			continue
+		}
+		if sd.Retry.Attempts < 0 || sd.Retry.Backoff < 0 || sd.Timeout < 0 {
+			fail(errors.New("negative retry or timeout setting"))
+			continue
+		}
+		fn, err := e.build(sd.Params)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if sd.Enabled != nil && !*sd.Enabled {
+			continue
+		}
+		p.steps = append(p.steps, step[T]{
+			name:    sd.Step,
+			fn:      fn.(StepFunc[T]),
+			retry:   sd.Retry,
+			timeout: time.Duration(sd.Timeout),
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return p, nil
+}
+
+// Name returns the name of the pipeline definition.
+func (p *Pipeline[T]) Name() string {
+	return p.name
+}
+
+// Steps returns the names of the enabled steps, in order.
+func (p *Pipeline[T]) Steps() []string {
+	names := make([]string, len(p.steps))
+	for i, s := range p.steps {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Run runs the pipeline on v.
+func (p *Pipeline[T]) Run(ctx context.Context, v T) immutable.Chain[T] {
+	return p.Apply(ctx, immutable.Wrap(v))
+}
+
+// Apply runs the enabled steps on c with Then, so the chain options apply to every step.
+// A failing step is retried as configured, and its last error is stored as a *StepError.
+// Retries stop when ctx is cancelled.
+func (p *Pipeline[T]) Apply(ctx context.Context, c immutable.Chain[T]) immutable.Chain[T] {
+	for _, s := range p.steps {
+		c = c.Then(func(v T) (T, error) {
+			return s.run(ctx, v)
+		})
+	}
+	return c
+}
+
+// run executes the step, retrying it on error.
+func (s step[T]) run(ctx context.Context, v T) (T, error) {
+	attempts := max(s.retry.Attempts, 1)
+	var err error
+	for i := range attempts {
+		if i > 0 && s.retry.Backoff > 0 {
+			select {
+			case <-time.After(time.Duration(s.retry.Backoff)):
+			case <-ctx.Done():
+				return v, &StepError{Step: s.name, Attempts: i, Err: errors.Join(err, ctx.Err())}
+			}
+		}
+		var out T
+		out, err = s.attempt(ctx, v)
+		if err == nil {
+			return out, nil
+		}
+		if ctx.Err() != nil {
+			return v, &StepError{Step: s.name, Attempts: i + 1, Err: err}
+		}
+	}
+	return v, &StepError{Step: s.name, Attempts: attempts, Err: err}
+}
+
+// attempt calls the step function once, under the step timeout if one is set.
+func (s step[T]) attempt(ctx context.Context, v T) (T, error) {
+	if s.timeout <= 0 {
+		return s.fn(ctx, v)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.fn(ctx, v)
+}
@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+type Record struct {
+	Name string
+	Tags []string
+}
+
+type tagParams struct {
+	Tag string `json:"tag"`
+}
+
+func testRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	must := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(RegisterIn(r, "normalize", func(_ context.Context, rec Record) (Record, error) {
+		rec.Name = strings.ToLower(strings.TrimSpace(rec.Name))
+		return rec, nil
+	}))
+	must(RegisterParamsIn(r, "tag", func(_ context.Context, rec Record, p tagParams) (Record, error) {
+		rec.Tags = append(rec.Tags, p.Tag)
+		return rec, nil
+	}))
+	must(RegisterIn(r, "reject", func(_ context.Context, rec Record) (Record, error) {
+		return rec, errors.New("rejected")
+	}))
+	must(RegisterIn(r, "length", func(_ context.Context, s string) (string, error) {
+		return s, nil
+	}))
+	return r
+}
+
+func TestLoad_Run(t *testing.T) {
+	def := `{
+		"name": "ingest",
+		"steps": [
+			{"step": "normalize"},
+			{"step": "tag", "params": {"tag": "first"}},
+			{"step": "reject", "enabled": false},
+			{"step": "tag", "params": {"tag": "second"}}
+		]
+	}`
+	p, err := Load[Record]([]byte(def), WithRegistry(testRegistry(t)))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p.Name() != "ingest" || strings.Join(p.Steps(), ",") != "normalize,tag,tag" {
+		t.Fatalf("unexpected pipeline %q %v", p.Name(), p.Steps())
+	}
+
+	rec, err := p.Run(context.Background(), Record{Name: "  Alice "}).Result()
+	if err != nil || rec.Name != "alice" || strings.Join(rec.Tags, ",") != "first,second" {
+		t.Fatalf("unexpected result %+v, %v", rec, err)
+	}
+}
+
+func TestLoad_Validation(t *testing.T) {
+	def := `{"steps": [
+		{"step": "missing"},
+		{"step": "length"},
+		{"step": "tag", "params": {"label": "x"}},
+		{"step": "normalize", "params": {"x": 1}},
+		{"step": "normalize", "retry": {"attempts": -1}}
+	]}`
+	_, err := Load[Record]([]byte(def), WithRegistry(testRegistry(t)))
+	if !errors.Is(err, ErrUnknownStep) {
+		t.Fatalf("expected ErrUnknownStep, got %v", err)
+	}
+	var tm *TypeMismatchError
+	if !errors.As(err, &tm) || tm.Step != "length" {
+		t.Fatalf("expected a TypeMismatchError for length, got %v", err)
+	}
+	for _, want := range []string{"step 2", "step 3", "step 4"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected an error for %s, got %v", want, err)
+		}
+	}
+
+	if _, err := Load[Record]([]byte(`{"steps": [{"step": "normalize", "timeout": "soon"}]}`), WithRegistry(testRegistry(t))); err == nil {
+		t.Fatal("expected an error for an invalid duration")
+	}
+}
+
+func TestRun_Retry(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	RegisterIn(r, "flaky", func(_ context.Context, n int) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("temporary")
+		}
+		return n + 1, nil
+	})
+
+	p, err := Load[int]([]byte(`{"steps": [{"step": "flaky", "retry": {"attempts": 3, "backoff": "1ms"}}]}`), WithRegistry(r))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, err := p.Run(context.Background(), 1).Result(); err != nil || got != 2 || calls != 3 {
+		t.Fatalf("expected success on the third attempt, got %d, %v after %d calls", got, err, calls)
+	}
+
+	calls = -10
+	_, err = p.Run(context.Background(), 1).Result()
+	var serr *StepError
+	if !errors.As(err, &serr) || serr.Step != "flaky" || serr.Attempts != 3 {
+		t.Fatalf("expected a StepError after 3 attempts, got %v", err)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	r := NewRegistry()
+	RegisterIn(r, "slow", func(ctx context.Context, n int) (int, error) {
+		select {
+		case <-time.After(time.Second):
+			return n, nil
+		case <-ctx.Done():
+			return n, ctx.Err()
+		}
+	})
+
+	p, _ := Load[int]([]byte(`{"steps": [{"step": "slow", "timeout": "5ms"}]}`), WithRegistry(r))
+	if _, err := p.Run(context.Background(), 1).Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestApply_ComposesWithChain(t *testing.T) {
+	p, _ := Load[Record]([]byte(`{"steps": [{"step": "normalize"}]}`), WithRegistry(testRegistry(t)))
+	errBoom := errors.New("boom")
+
+	got := p.Apply(context.Background(), immutable.Wrap(Record{Name: "X"})).Unwrap()
+	if got.Name != "x" {
+		t.Fatalf("expected x, got %q", got.Name)
+	}
+	if _, err := p.Apply(context.Background(), immutable.Wrap(Record{}).WithError(errBoom)).Result(); !errors.Is(err, errBoom) {
+		t.Fatalf("expected the chain error to pass through, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+var (
+	// ErrDuplicateStep is returned when a step name is registered twice.
+	ErrDuplicateStep = errors.New("duplicate step")
+	// ErrUnknownStep is reported by Load for a step name that is not registered.
+	ErrUnknownStep = errors.New("unknown step")
+)
+
+// StepFunc is a registered step, transforming the value of a pipeline.
+type StepFunc[T any] func(context.Context, T) (T, error)
+
+// Registry maps step names to step functions. It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	steps map[string]entry
+}
+
+// entry is a registered step. build decodes the step parameters and returns its StepFunc.
+type entry struct {
+	typ   reflect.Type
+	build func(params json.RawMessage) (any, error)
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{steps: make(map[string]entry)}
+}
+
+// Default is the registry used by Register, RegisterParams and Load.
+var Default = NewRegistry()
+
+// Register adds the step f operating on T to the default registry under name.
+func Register[T any](name string, f StepFunc[T]) error {
+	return RegisterIn(Default, name, f)
+}
+
+// RegisterParams adds to the default registry a step taking parameters of type P,
+// decoded from the step definition when the pipeline is loaded.
+func RegisterParams[T, P any](name string, f func(context.Context, T, P) (T, error)) error {
+	return RegisterParamsIn(Default, name, f)
+}
+
+// RegisterIn works like Register on the registry r. A step without parameters rejects any given in a definition.
+func RegisterIn[T any](r *Registry, name string, f StepFunc[T]) error {
+	if f == nil {
+		return fmt.Errorf("step %q: %w", name, immutable.ErrNilFunc)
+	}
+	return r.add(name, entry{
+		typ: reflect.TypeFor[T](),
+		build: func(params json.RawMessage) (any, error) {
+			if len(bytes.TrimSpace(params)) > 0 && !bytes.Equal(bytes.TrimSpace(params), []byte("null")) {
+				return nil, errors.New("step takes no parameters")
+			}
+			return f, nil
+		},
+	})
+}
+
+// RegisterParamsIn works like RegisterParams on the registry r.
+// Unknown parameter fields are rejected when the pipeline is loaded.
+func RegisterParamsIn[T, P any](r *Registry, name string, f func(context.Context, T, P) (T, error)) error {
+	if f == nil {
+		return fmt.Errorf("step %q: %w", name, immutable.ErrNilFunc)
+	}
+	return r.add(name, entry{
+		typ: reflect.TypeFor[T](),
+		build: func(params json.RawMessage) (any, error) {
+			var p P
+			if len(params) > 0 {
+				dec := json.NewDecoder(bytes.NewReader(params))
+				dec.DisallowUnknownFields()
+				if err := dec.Decode(&p); err != nil {
+					return nil, fmt.Errorf("invalid parameters: %w", err)
+				}
+			}
+			return StepFunc[T](func(ctx context.Context, v T) (T, error) {
+				return f(ctx, v, p)
+			}), nil
+		},
+	})
+}
+
+func (r *Registry) add(name string, e entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.steps[name]; ok {
+		return fmt.Errorf("step %q: %w", name, ErrDuplicateStep)
+	}
+	r.steps[name] = e
+	return nil
+}
+
+func (r *Registry) lookup(name string) (entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.steps[name]
+	return e, ok
+}
@@ -0,0 +1,40 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+func TestRegisterIn(t *testing.T) {
+	r := NewRegistry()
+	if err := RegisterIn(r, "test.upper", func(_ context.Context, s string) (string, error) {
+		return strings.ToUpper(s), nil
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := RegisterIn(r, "test.upper", func(_ context.Context, s string) (string, error) { return s, nil }); !errors.Is(err, ErrDuplicateStep) {
+		t.Fatalf("expected ErrDuplicateStep, got %v", err)
+	}
+
+	p, err := Load[string]([]byte(`{"steps": [{"step": "test.upper"}]}`), WithRegistry(r))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := p.Run(context.Background(), "go").Unwrap(); got != "GO" {
+		t.Fatalf("expected GO, got %q", got)
+	}
+}
+
+func TestRegister_NilFunc(t *testing.T) {
+	r := NewRegistry()
+	if err := RegisterIn[string](r, "nil", nil); !errors.Is(err, immutable.ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc, got %v", err)
+	}
+	if err := RegisterParamsIn[string, int](r, "nil", nil); !errors.Is(err, immutable.ErrNilFunc) {
+		t.Fatalf("expected ErrNilFunc, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package store
+
+import "log"
+
+// ThunkFunc is the payload of a thunk action, see Thunk.
+type ThunkFunc[S any] func(api API[S]) error
+
+// ThunkAction returns an action running fn through the Thunk middleware.
+func ThunkAction[S any](fn ThunkFunc[S]) Action {
+	return Action{Type: "thunk", Payload: fn}
+}
+
+// Thunk returns middleware running the ThunkFunc payloads of actions instead of reducing them.
+// The thunk receives the store, so it can read the state and dispatch, including later from another goroutine
+// for asynchronous work. Dispatch returns the error of the thunk.
+func Thunk[S any]() Middleware[S] {
+	return func(api API[S], next Dispatcher) Dispatcher {
+		return func(a Action) error {
+			if fn, ok := a.Payload.(ThunkFunc[S]); ok {
+				if fn == nil {
+					return nil
+				}
+				return fn(api)
+			}
+			return next(a)
+		}
+	}
+}
+
+// Logger returns middleware logging every action with logf, along with its error or the resulting state.
+// A nil logf logs with log.Printf.
+func Logger[S any](logf func(format string, args ...any)) Middleware[S] {
+	if logf == nil {
+		logf = log.Printf
+	}
+	return func(api API[S], next Dispatcher) Dispatcher {
+		return func(a Action) error {
+			if err := next(a); err != nil {
+				logf("[store] %s failed: %v", a.Type, err)
+				return err
+			}
+			logf("[store] %s: %+v", a.Type, api.State())
+			return nil
+		}
+	}
+}
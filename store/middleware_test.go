@@ -0,0 +1,72 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var lines []string
+	logf := func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	st := New(&Todos{}, nil, WithReducer(todoReducer), WithMiddleware(Logger[Todos](logf)))
+
+	st.Dispatch(Action{Type: "add", Payload: "a"})
+	st.Dispatch(Action{Type: "add"})
+
+	if len(lines) != 2 || !strings.Contains(lines[0], "add: {Items:[a]") || !strings.Contains(lines[1], "add failed: empty todo") {
+		t.Fatalf("unexpected log %q", lines)
+	}
+}
+
+func TestThunk(t *testing.T) {
+	st := New(&Todos{}, nil, WithReducer(todoReducer), WithMiddleware(Thunk[Todos]()))
+
+	done := make(chan error)
+	fetch := ThunkAction(ThunkFunc[Todos](func(api API[Todos]) error {
+		if err := api.Dispatch(Action{Type: "add", Payload: "sync"}); err != nil {
+			return err
+		}
+		go func() {
+			done <- api.Dispatch(Action{Type: "add", Payload: "async"})
+		}()
+		return nil
+	}))
+
+	if err := st.Dispatch(fetch); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := st.State(); strings.Join(got.Items, ",") != "sync,async" {
+		t.Fatalf("unexpected state %+v", got)
+	}
+
+	errFetch := errors.New("fetch failed")
+	failing := ThunkAction(ThunkFunc[Todos](func(API[Todos]) error { return errFetch }))
+	if err := st.Dispatch(failing); !errors.Is(err, errFetch) {
+		t.Fatalf("expected the thunk error, got %v", err)
+	}
+}
+
+func TestMiddleware_Order(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware[Todos] {
+		return func(_ API[Todos], next Dispatcher) Dispatcher {
+			return func(a Action) error {
+				order = append(order, name)
+				return next(a)
+			}
+		}
+	}
+	st := New(&Todos{}, nil, WithMiddleware(mw("outer"), mw("inner")))
+	st.Dispatch(Action{Type: "x"})
+
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Fatalf("expected outer then inner, got %v", order)
+	}
+}
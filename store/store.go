@@ -0,0 +1,206 @@
+// Package store provides a Redux-style state container over mutable.Wrapper.
+//
+// Dispatched actions run through the reducers of the store as Wrapper.Then steps,
+// grouped with Wrapper.Atomic so that a failing dispatch leaves the state untouched.
+// Reducer failures go through the error handler of the store, like any wrapper error.
+// Dispatches are serialised, and subscribers are notified after each successful one, in dispatch order.
+package store
+
+import (
+	"slices"
+	"sync"
+
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+// Action describes a state change. Type identifies it, and Payload carries its data.
+type Action struct {
+	Type    string
+	Payload any
+}
+
+// Reducer applies an action to the state, as a Wrapper.Then step.
+// Reducers receive every action and return the state unchanged for the ones they do not handle.
+type Reducer[S any] func(*S, Action) (*S, error)
+
+// Dispatcher dispatches an action.
+type Dispatcher func(Action) error
+
+// Middleware wraps the dispatcher of a store, see WithMiddleware.
+// It may inspect, transform, delay or swallow actions before calling next.
+type Middleware[S any] func(api API[S], next Dispatcher) Dispatcher
+
+// API is the view of a store given to middleware and thunks.
+type API[S any] interface {
+	Dispatch(a Action) error
+	State() S
+}
+
+// Option configures a Store.
+type Option[S any] func(*Store[S])
+
+// WithReducer adds a reducer. Reducers run in the order they were added.
+func WithReducer[S any](r Reducer[S]) Option[S] {
+	return func(s *Store[S]) {
+		if r != nil {
+			s.reducers = append(s.reducers, r)
+		}
+	}
+}
+
+// WithMiddleware adds middleware. The first one added is the outermost, seeing actions first.
+func WithMiddleware[S any](m ...Middleware[S]) Option[S] {
+	return func(s *Store[S]) {
+		for _, mw := range m {
+			if mw != nil {
+				s.middleware = append(s.middleware, mw)
+			}
+		}
+	}
+}
+
+// WithCopy sets how the state is snapshotted before each dispatch, to restore it on failure.
+// The default, mutable.ShallowCopy, suits reducers that replace rather than update slices and maps in place;
+// use mutable.DeepCopy otherwise.
+func WithCopy[S any](cp mutable.CopyFunc[S]) Option[S] {
+	return func(s *Store[S]) {
+		if cp != nil {
+			s.cp = cp
+		}
+	}
+}
+
+// WithWrapperOptions passes options such as mutable.WithPanicSafe or mutable.WithHistory to the state wrapper.
+func WithWrapperOptions[S any](opts ...mutable.Option) Option[S] {
+	return func(s *Store[S]) {
+		s.wrapperOpts = append(s.wrapperOpts, opts...)
+	}
+}
+
+// Store holds a state of type S, changed only by dispatching actions. It is safe for concurrent use.
+type Store[S any] struct {
+	reducers    []Reducer[S]
+	middleware  []Middleware[S]
+	cp          mutable.CopyFunc[S]
+	wrapperOpts []mutable.Option
+
+	// mu guards state; it is held for writing by the single dispatch in progress.
+	mu       sync.RWMutex
+	state    mutable.Wrapper[S]
+	dispatch Dispatcher
+
+	// notify orders the notifications of consecutive dispatches.
+	notify sync.Mutex
+	subsMu sync.Mutex
+	subs   []*subscriber[S]
+}
+
+type subscriber[S any] struct {
+	fn func(S)
+}
+
+// New returns a store holding initial, or a zero S if it is nil.
+// errHandler is given to the state wrapper, see mutable.New.
+func New[S any](initial *S, errHandler func(error) error, opts ...Option[S]) *Store[S] {
+	if initial == nil {
+		initial = new(S)
+	}
+	s := &Store[S]{cp: mutable.ShallowCopy[S]}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	s.state = mutable.New(initial, errHandler, s.wrapperOpts...)
+
+	s.dispatch = s.reduce
+	for _, mw := range slices.Backward(s.middleware) {
+		s.dispatch = mw(s, s.dispatch)
+	}
+	return s
+}
+
+// Dispatch passes a through the middleware and then the reducers.
+// If a reducer fails, the state is restored and the error, as returned by the error handler, is returned.
+func (s *Store[S]) Dispatch(a Action) error {
+	return s.dispatch(a)
+}
+
+// State returns a shallow copy of the current state.
+func (s *Store[S]) State() S {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value()
+}
+
+// Subscribe registers fn to be called with the new state after every successful dispatch,
+// and returns a function that unregisters it.
+// Subscribers are called one at a time, in dispatch order; they may read the store,
+// but must dispatch from another goroutine, since the next notification waits for them.
+func (s *Store[S]) Subscribe(fn func(S)) (unsubscribe func()) {
+	if fn == nil {
+		return func() {}
+	}
+	sub := &subscriber[S]{fn: fn}
+	s.subsMu.Lock()
+	s.subs = append(s.subs, sub)
+	s.subsMu.Unlock()
+	return func() {
+		s.subsMu.Lock()
+		defer s.subsMu.Unlock()
+		s.subs = slices.DeleteFunc(s.subs, func(o *subscriber[S]) bool { return o == sub })
+	}
+}
+
+// reduce is the innermost dispatcher, running the reducers and then notifying the subscribers.
+func (s *Store[S]) reduce(a Action) error {
+	state, err := s.apply(a)
+	if err != nil {
+		return err
+	}
+	defer s.notify.Unlock()
+
+	s.subsMu.Lock()
+	subs := slices.Clone(s.subs)
+	s.subsMu.Unlock()
+	for _, sub := range subs {
+		sub.fn(state)
+	}
+	return nil
+}
+
+// apply runs the reducers as one atomic group of Then steps and stores the new state.
+// On success it returns holding the notification lock, taken before the state is released,
+// so that the subscribers see the states in dispatch order.
+// The state lock is released even if a reducer panics.
+func (s *Store[S]) apply(a Action) (S, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.state.Atomic(s.cp, func(w mutable.Wrapper[S]) mutable.Wrapper[S] {
+		for _, r := range s.reducers {
+			w = w.Then(func(st *S) (*S, error) {
+				return r(st, a)
+			})
+		}
+		return w
+	})
+	if err := next.HasError(); err != nil {
+		var zero S
+		return zero, err
+	}
+	s.state = next
+
+	s.notify.Lock()
+	return s.value(), nil
+}
+
+// value returns a copy of the state. The lock must be held.
+func (s *Store[S]) value() S {
+	v, _ := s.state.Result()
+	if v == nil {
+		var zero S
+		return zero
+	}
+	return *v
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	mutable "github.com/KeibiSoft/go-fp/mutable"
+)
+
+type Todos struct {
+	Items []string
+	Done  int
+}
+
+var errEmpty = errors.New("empty todo")
+
+func todoReducer(s *Todos, a Action) (*Todos, error) {
+	switch a.Type {
+	case "add":
+		text, _ := a.Payload.(string)
+		if text == "" {
+			return s, errEmpty
+		}
+		s.Items = append(s.Items, text)
+	case "complete":
+		s.Done++
+	}
+	return s, nil
+}
+
+func limitReducer(s *Todos, a Action) (*Todos, error) {
+	if len(s.Items) > 2 {
+		return s, errors.New("too many todos")
+	}
+	return s, nil
+}
+
+func TestStore_Dispatch(t *testing.T) {
+	st := New(&Todos{}, nil, WithReducer(todoReducer))
+
+	if err := st.Dispatch(Action{Type: "add", Payload: "write tests"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	st.Dispatch(Action{Type: "complete"})
+
+	got := st.State()
+	if len(got.Items) != 1 || got.Done != 1 {
+		t.Fatalf("unexpected state %+v", got)
+	}
+}
+
+func TestStore_FailureRestoresState(t *testing.T) {
+	var handled []error
+	st := New(&Todos{}, func(err error) error {
+		handled = append(handled, err)
+		return err
+	}, WithReducer(todoReducer), WithReducer(limitReducer), WithCopy(mutable.DeepCopy[Todos]))
+
+	st.Dispatch(Action{Type: "add", Payload: "a"})
+	st.Dispatch(Action{Type: "add", Payload: "b"})
+	if err := st.Dispatch(Action{Type: "add"}); !errors.Is(err, errEmpty) {
+		t.Fatalf("expected errEmpty, got %v", err)
+	}
+	if err := st.Dispatch(Action{Type: "add", Payload: "c"}); err == nil {
+		t.Fatal("expected the limit reducer to fail")
+	}
+
+	if got := st.State(); len(got.Items) != 2 {
+		t.Fatalf("expected the failed dispatches to leave 2 items, got %+v", got)
+	}
+	if len(handled) != 2 {
+		t.Fatalf("expected the error handler to see both failures, got %v", handled)
+	}
+
+	// The store stays usable after a failure.
+	if err := st.Dispatch(Action{Type: "complete"}); err != nil || st.State().Done != 1 {
+		t.Fatalf("expected the store to keep working, got %v", err)
+	}
+}
+
+func TestStore_ErrHandlerSuppresses(t *testing.T) {
+	st := New(&Todos{}, func(error) error { return nil }, WithReducer(todoReducer))
+	if err := st.Dispatch(Action{Type: "add"}); err != nil {
+		t.Fatalf("expected the error to be suppressed, got %v", err)
+	}
+}
+
+func TestStore_Subscribe(t *testing.T) {
+	st := New(&Todos{}, nil, WithReducer(todoReducer))
+	var seen []int
+	unsubscribe := st.Subscribe(func(s Todos) {
+		seen = append(seen, len(s.Items))
+		if st.State().Done != s.Done {
+			t.Error("expected subscribers to be able to read the store")
+		}
+	})
+
+	st.Dispatch(Action{Type: "add", Payload: "a"})
+	st.Dispatch(Action{Type: "add"})
+	st.Dispatch(Action{Type: "add", Payload: "b"})
+	unsubscribe()
+	st.Dispatch(Action{Type: "add", Payload: "c"})
+
+	if len(seen) != 2 || seen[0] != 1 || seen[1] != 2 {
+		t.Fatalf("expected notifications for the 2 successful dispatches, got %v", seen)
+	}
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	st := New(&Todos{}, nil, WithReducer(todoReducer))
+	var mu sync.Mutex
+	last := 0
+	ordered := true
+	st.Subscribe(func(s Todos) {
+		mu.Lock()
+		defer mu.Unlock()
+		if s.Done != last+1 {
+			ordered = false
+		}
+		last = s.Done
+	})
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st.Dispatch(Action{Type: "complete"})
+			st.State()
+		}()
+	}
+	wg.Wait()
+
+	if st.State().Done != 50 || !ordered {
+		t.Fatalf("expected 50 serialised dispatches notified in order, got %d, ordered=%v", st.State().Done, ordered)
+	}
+}
+
+func TestStore_PanicSafe(t *testing.T) {
+	st := New(&Todos{}, nil, WithReducer(func(s *Todos, _ Action) (*Todos, error) {
+		s.Done = 99
+		panic("boom")
+	}), WithWrapperOptions[Todos](mutable.WithPanicSafe()))
+
+	var pe *mutable.PanicError
+	if err := st.Dispatch(Action{Type: "x"}); !errors.As(err, &pe) {
+		t.Fatalf("expected *PanicError, got %v", err)
+	}
+	if st.State().Done != 0 {
+		t.Fatal("expected the state to be restored after the panic")
+	}
+}
+
+func TestStore_PanicReleasesLock(t *testing.T) {
+	st := New(&Todos{}, nil, WithReducer(func(s *Todos, a Action) (*Todos, error) {
+		if a.Type == "panic" {
+			panic("boom")
+		}
+		s.Done++
+		return s, nil
+	}))
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected the panic to reach the caller")
+			}
+		}()
+		st.Dispatch(Action{Type: "panic"})
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- st.Dispatch(Action{Type: "complete"}) }()
+	select {
+	case err := <-done:
+		if err != nil || st.State().Done != 1 {
+			t.Fatalf("expected the next dispatch to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the store to be unlocked after a panicking reducer")
+	}
+}
@@ -0,0 +1,172 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the time source of the windowing stages, see WithClock.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single-shot timer created by a Clock, like time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// SystemClock returns the Clock backed by the time package, used by default.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+func (t systemTimer) Reset(d time.Duration) bool {
+	return t.t.Reset(d)
+}
+
+// ManualClock is a Clock that only moves when told to, for deterministic tests.
+// Timers fire during Advance once their deadline is reached.
+type ManualClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers map[*manualTimer]struct{}
+}
+
+// NewManualClock returns a ManualClock set to start.
+func NewManualClock(start time.Time) *ManualClock {
+	c := &ManualClock{now: start, timers: make(map[*manualTimer]struct{})}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	t := &manualTimer{clock: c, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers whose deadline is reached.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for t := range c.timers {
+		if !t.at.After(c.now) {
+			t.fire(c.now)
+		}
+	}
+}
+
+// WaitForTimers blocks until at least n timers are pending, i.e. created or reset and not yet fired or stopped.
+// Tests use it to make sure a stage has armed its timer before calling Advance.
+func (c *ManualClock) WaitForTimers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// WaitForDeadline blocks until a timer is pending with the given deadline.
+// Tests use it to make sure a stage has reset its timer after receiving a value.
+func (c *ManualClock) WaitForDeadline(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for !c.hasDeadline(at) {
+		c.cond.Wait()
+	}
+}
+
+func (c *ManualClock) hasDeadline(at time.Time) bool {
+	for t := range c.timers {
+		if t.at.Equal(at) {
+			return true
+		}
+	}
+	return false
+}
+
+type manualTimer struct {
+	clock *ManualClock
+	ch    chan time.Time
+	at    time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *manualTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return t.stop()
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := t.stop()
+	t.at = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+		return active
+	}
+	c.timers[t] = struct{}{}
+	c.cond.Broadcast()
+	return active
+}
+
+// stop deactivates t and drops an unread tick, like time.Timer.Stop since Go 1.23.
+// The clock lock must be held.
+func (t *manualTimer) stop() bool {
+	_, active := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	select {
+	case <-t.ch:
+	default:
+	}
+	return active
+}
+
+// fire delivers now on the timer channel and deactivates it. The clock lock must be held.
+func (t *manualTimer) fire(now time.Time) {
+	delete(t.clock.timers, t)
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
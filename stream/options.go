@@ -0,0 +1,55 @@
+package stream
+
+// Option configures a pipeline stage.
+type Option func(*options)
+
+type options struct {
+	workers int
+	ordered bool
+	buffer  int
+	clock   Clock
+}
+
+// WithWorkers runs the stage function on n goroutines. Values lower than one mean one worker.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// WithOrdered makes a stage with several workers emit its results in input order.
+// Without it results are emitted as soon as they are ready.
+func WithOrdered() Option {
+	return func(o *options) {
+		o.ordered = true
+	}
+}
+
+// WithBuffer sets the capacity of the output channel of the stage.
+func WithBuffer(n int) Option {
+	return func(o *options) {
+		o.buffer = n
+	}
+}
+
+// WithClock sets the time source of the windowing stages, SystemClock by default.
+// Pass a ManualClock to drive them deterministically in tests.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		if c != nil {
+			o.clock = c
+		}
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{workers: 1, clock: SystemClock()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	o.workers = max(o.workers, 1)
+	o.buffer = max(o.buffer, 0)
+	return o
+}
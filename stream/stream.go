@@ -0,0 +1,191 @@
+// Package stream provides concurrent pipeline stages over channels of immutable.Chain values.
+//
+// Each stage reads chains from an input channel and writes chains to the output channel it returns.
+// Failed chains flow through the stages unchanged, and a step error fails only its own chain,
+// so one bad item never tears down the pipeline.
+// A stage stops and closes its output when its input is closed or its context is cancelled.
+package stream
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+// Source emits the chains of seq on the returned channel, closing it at the end of seq.
+func Source[T any](ctx context.Context, seq iter.Seq[immutable.Chain[T]], opts ...Option) <-chan immutable.Chain[T] {
+	o := newOptions(opts)
+	out := make(chan immutable.Chain[T], o.buffer)
+	go func() {
+		defer close(out)
+		if seq == nil {
+			return
+		}
+		for c := range seq {
+			if !send(ctx, out, c) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Seq returns an iterator over the chains received from in.
+// Stopping the iteration early leaves the upstream stages blocked until their context is cancelled.
+func Seq[T any](in <-chan immutable.Chain[T]) iter.Seq[immutable.Chain[T]] {
+	return func(yield func(immutable.Chain[T]) bool) {
+		for c := range in {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// Bind applies f to every successful chain received from in, like immutable.Bind.
+// Failed chains are forwarded with their error.
+func Bind[T any, U any](ctx context.Context, in <-chan immutable.Chain[T], f func(T) immutable.Chain[U], opts ...Option) <-chan immutable.Chain[U] {
+	return run(ctx, in, func(c immutable.Chain[T]) (immutable.Chain[U], bool) {
+		return immutable.Bind(c, f), true
+	}, newOptions(opts))
+}
+
+// Map applies the fallible f to every successful chain received from in.
+// An error returned by f fails the chain of that item only.
+// If f is nil, chains are mapped to the zero value of U, or fail with ErrNilFunc in strict mode.
+func Map[T any, U any](ctx context.Context, in <-chan immutable.Chain[T], f func(context.Context, T) (U, error), opts ...Option) <-chan immutable.Chain[U] {
+	var step func(T) immutable.Chain[U]
+	if f != nil {
+		step = func(v T) immutable.Chain[U] {
+			u, err := f(ctx, v)
+			return immutable.Wrap(u).WithError(err)
+		}
+	}
+	return Bind(ctx, in, step, opts...)
+}
+
+// Filter drops the successful chains received from in for which pred does not hold.
+// Failed chains are forwarded, so their errors still reach the consumer.
+// If pred is nil, every chain is forwarded.
+func Filter[T any](ctx context.Context, in <-chan immutable.Chain[T], pred func(T) bool, opts ...Option) <-chan immutable.Chain[T] {
+	return run(ctx, in, func(c immutable.Chain[T]) (immutable.Chain[T], bool) {
+		if pred == nil || c.IsFailure() {
+			return c, true
+		}
+		keep := false
+		c = c.Map(func(v T) T {
+			keep = pred(v)
+			return v
+		})
+		return c, keep || c.IsFailure()
+	}, newOptions(opts))
+}
+
+// run starts a stage applying step to every chain received from in.
+// Chains for which step reports false are dropped.
+func run[T any, U any](ctx context.Context, in <-chan immutable.Chain[T], step func(immutable.Chain[T]) (immutable.Chain[U], bool), o options) <-chan immutable.Chain[U] {
+	out := make(chan immutable.Chain[U], o.buffer)
+	if o.ordered && o.workers > 1 {
+		go runOrdered(ctx, in, out, step, o)
+	} else {
+		go runUnordered(ctx, in, out, step, o)
+	}
+	return out
+}
+
+func runUnordered[T any, U any](ctx context.Context, in <-chan immutable.Chain[T], out chan<- immutable.Chain[U], step func(immutable.Chain[T]) (immutable.Chain[U], bool), o options) {
+	var wg sync.WaitGroup
+	wg.Add(o.workers)
+	for range o.workers {
+		go func() {
+			defer wg.Done()
+			for {
+				c, ok := receive(ctx, in)
+				if !ok {
+					return
+				}
+				if r, keep := step(c); keep && !send(ctx, out, r) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(out)
+}
+
+// result is the outcome of a step, delivered to the collector of an ordered stage.
+type result[U any] struct {
+	c    immutable.Chain[U]
+	keep bool
+}
+
+type job[T any, U any] struct {
+	c   immutable.Chain[T]
+	res chan result[U]
+}
+
+// runOrdered dispatches the input to the workers and queues a result slot per item,
+// which the collector drains in input order.
+func runOrdered[T any, U any](ctx context.Context, in <-chan immutable.Chain[T], out chan<- immutable.Chain[U], step func(immutable.Chain[T]) (immutable.Chain[U], bool), o options) {
+	jobs := make(chan job[T, U])
+	pending := make(chan chan result[U], o.workers+o.buffer)
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for {
+			c, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			j := job[T, U]{c: c, res: make(chan result[U], 1)}
+			if !send(ctx, pending, j.res) || !send(ctx, jobs, j) {
+				return
+			}
+		}
+	}()
+
+	for range o.workers {
+		go func() {
+			for j := range jobs {
+				r, keep := step(j.c)
+				j.res <- result[U]{c: r, keep: keep}
+			}
+		}()
+	}
+
+	defer close(out)
+	for res := range pending {
+		r, ok := receive(ctx, res)
+		if !ok {
+			return
+		}
+		if r.keep && !send(ctx, out, r.c) {
+			return
+		}
+	}
+}
+
+// send delivers v on ch, reporting false if ctx is cancelled first.
+func send[V any](ctx context.Context, ch chan<- V, v V) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// receive reads from ch, reporting false if ch is closed or ctx is cancelled first.
+func receive[V any](ctx context.Context, ch <-chan V) (V, bool) {
+	select {
+	case v, ok := <-ch:
+		return v, ok
+	case <-ctx.Done():
+		var zero V
+		return zero, false
+	}
+}
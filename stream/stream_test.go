@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+func ints(n int) []immutable.Chain[int] {
+	out := make([]immutable.Chain[int], n)
+	for i := range out {
+		out[i] = immutable.Wrap(i)
+	}
+	return out
+}
+
+func values[T any](t *testing.T, in <-chan immutable.Chain[T]) ([]T, []error) {
+	t.Helper()
+	var vals []T
+	var errs []error
+	for c := range in {
+		v, err := c.Result()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		vals = append(vals, v)
+	}
+	return vals, errs
+}
+
+func TestMap_Ordered(t *testing.T) {
+	ctx := context.Background()
+	src := Source(ctx, slices.Values(ints(50)))
+
+	// Item 0 finishes only after item 1, so only ordering restores the input order.
+	done1 := make(chan struct{})
+	out := Map(ctx, src, func(_ context.Context, v int) (string, error) {
+		switch v {
+		case 0:
+			select {
+			case <-done1:
+			case <-time.After(5 * time.Second):
+				return "", errors.New("item 1 was not processed while item 0 was pending")
+			}
+		case 1:
+			defer close(done1)
+		}
+		return strconv.Itoa(v), nil
+	}, WithWorkers(8), WithOrdered(), WithBuffer(4))
+
+	got, errs := values(t, out)
+	if len(errs) != 0 || len(got) != 50 {
+		t.Fatalf("expected 50 values, got %d, %v", len(got), errs)
+	}
+	for i, s := range got {
+		if s != strconv.Itoa(i) {
+			t.Fatalf("expected input order, got %v", got)
+		}
+	}
+}
+
+func TestMap_UnorderedUsesWorkers(t *testing.T) {
+	ctx := context.Background()
+	// The first four calls wait until all of them have started, which needs four concurrent workers.
+	var started atomic.Int32
+	all := make(chan struct{})
+	out := Map(ctx, Source(ctx, slices.Values(ints(20))), func(_ context.Context, v int) (int, error) {
+		n := started.Add(1)
+		if n == 4 {
+			close(all)
+		}
+		if n <= 4 {
+			select {
+			case <-all:
+			case <-time.After(5 * time.Second):
+				return 0, errors.New("the workers did not run concurrently")
+			}
+		}
+		return v * 2, nil
+	}, WithWorkers(4))
+
+	got, errs := values(t, out)
+	slices.Sort(got)
+	if len(errs) != 0 || len(got) != 20 || got[19] != 38 {
+		t.Fatalf("expected 20 doubled values, got %v, %v", got, errs)
+	}
+}
+
+func TestMap_ErrorsFlowThrough(t *testing.T) {
+	ctx := context.Background()
+	errIn := errors.New("bad input")
+	errOdd := errors.New("odd")
+	in := []immutable.Chain[int]{immutable.Wrap(2), immutable.Wrap(0).WithError(errIn), immutable.Wrap(3), immutable.Wrap(4)}
+
+	out := Map(ctx, Source(ctx, slices.Values(in)), func(_ context.Context, v int) (int, error) {
+		if v%2 == 1 {
+			return 0, errOdd
+		}
+		return v * 10, nil
+	}, WithWorkers(2), WithOrdered())
+
+	got, errs := values(t, out)
+	if !slices.Equal(got, []int{20, 40}) {
+		t.Fatalf("expected [20 40], got %v", got)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], errIn) || !errors.Is(errs[1], errOdd) {
+		t.Fatalf("expected both errors in order, got %v", errs)
+	}
+}
+
+func TestFilterBind(t *testing.T) {
+	ctx := context.Background()
+	errIn := errors.New("bad input")
+	in := append(ints(6), immutable.Wrap(0).WithError(errIn))
+
+	even := Filter(ctx, Source(ctx, slices.Values(in)), func(v int) bool { return v%2 == 0 })
+	halves := Bind(ctx, even, func(v int) immutable.Chain[int] { return immutable.Wrap(v / 2) })
+
+	got, errs := values(t, halves)
+	if !slices.Equal(got, []int{0, 1, 2}) || len(errs) != 1 || !errors.Is(errs[0], errIn) {
+		t.Fatalf("unexpected output %v, %v", got, errs)
+	}
+}
+
+func TestMap_Cancel(t *testing.T) {
+	in := make(chan immutable.Chain[int])
+
+	for _, opts := range [][]Option{nil, {WithWorkers(3), WithOrdered()}} {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := Map(ctx, in, func(_ context.Context, v int) (int, error) { return v, nil }, opts...)
+		cancel()
+		select {
+		case _, ok := <-out:
+			if ok {
+				t.Fatal("expected no output after cancellation")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the output to be closed after cancellation")
+		}
+	}
+}
+
+func TestSeq_Collect(t *testing.T) {
+	ctx := context.Background()
+	got, err := immutable.Collect(Seq(Source(ctx, slices.Values(ints(3))))).Result()
+	if err != nil || !slices.Equal(got, []int{0, 1, 2}) {
+		t.Fatalf("expected [0 1 2], got %v, %v", got, err)
+	}
+}
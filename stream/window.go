@@ -0,0 +1,252 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+// The windowing stages group the successful chains received from their input into Chain[[]T] values,
+// carrying the merged options and deferred actions of the grouped chains.
+// Failed chains are never put in a group: they are forwarded immediately, as a failed Chain[[]T].
+// Pending values are emitted when the input is closed, but not when the context is cancelled.
+// Time is read from the clock set with WithClock.
+
+// ErrInvalidDuration is stored in the single chain emitted by a windowing stage given a non-positive duration.
+var ErrInvalidDuration = errors.New("stream: non-positive duration")
+
+// Batch groups values by n, emitting a batch early once maxWait has passed since its first value.
+// An n lower than one puts no limit on the size, and a non-positive maxWait none on the wait.
+func Batch[T any](ctx context.Context, in <-chan immutable.Chain[T], n int, maxWait time.Duration, opts ...Option) <-chan immutable.Chain[[]T] {
+	return runWindow(ctx, in, newOptions(opts), 0, func(w *windower[T], c immutable.Chain[T]) bool {
+		w.add(c)
+		if len(w.pending) == 1 && maxWait > 0 {
+			w.arm(maxWait)
+		}
+		if n > 0 && len(w.pending) >= n {
+			w.disarm()
+			return w.flush()
+		}
+		return true
+	}, func(w *windower[T]) bool {
+		return w.flush()
+	})
+}
+
+// Tumbling groups values into consecutive, non-overlapping windows of the given size,
+// starting when the stage starts. Empty windows are not emitted.
+// A non-positive size makes the stage fail with ErrInvalidDuration.
+func Tumbling[T any](ctx context.Context, in <-chan immutable.Chain[T], size time.Duration, opts ...Option) <-chan immutable.Chain[[]T] {
+	if err := positive("size", size); err != nil {
+		return failed(ctx, in, newOptions(opts), err)
+	}
+	return runWindow(ctx, in, newOptions(opts), size, func(w *windower[T], c immutable.Chain[T]) bool {
+		w.add(c)
+		return true
+	}, func(w *windower[T]) bool {
+		w.arm(size)
+		return w.flush()
+	})
+}
+
+// Sliding emits, every period, the values received during the last size.
+// Windows overlap when period is shorter than size; empty windows are not emitted.
+// A non-positive size or period makes the stage fail with ErrInvalidDuration.
+func Sliding[T any](ctx context.Context, in <-chan immutable.Chain[T], size, period time.Duration, opts ...Option) <-chan immutable.Chain[[]T] {
+	if err := errors.Join(positive("size", size), positive("period", period)); err != nil {
+		return failed(ctx, in, newOptions(opts), err)
+	}
+	return runWindow(ctx, in, newOptions(opts), period, func(w *windower[T], c immutable.Chain[T]) bool {
+		now := w.add(c)
+		w.evict(now.Add(-size))
+		return true
+	}, func(w *windower[T]) bool {
+		w.arm(period)
+		w.evict(w.clock.Now().Add(-size))
+		return w.emit(w.pending)
+	})
+}
+
+// Debounce emits the values received so far once no value has arrived for quiet.
+// A non-positive quiet makes the stage fail with ErrInvalidDuration.
+func Debounce[T any](ctx context.Context, in <-chan immutable.Chain[T], quiet time.Duration, opts ...Option) <-chan immutable.Chain[[]T] {
+	if err := positive("quiet", quiet); err != nil {
+		return failed(ctx, in, newOptions(opts), err)
+	}
+	return runWindow(ctx, in, newOptions(opts), 0, func(w *windower[T], c immutable.Chain[T]) bool {
+		w.add(c)
+		w.arm(quiet)
+		return true
+	}, func(w *windower[T]) bool {
+		return w.flush()
+	})
+}
+
+// Throttle emits at most one group per interval.
+// A value arriving after a quiet interval is emitted at once;
+// the values arriving within the following interval are emitted together at its end.
+// A non-positive interval makes the stage fail with ErrInvalidDuration.
+func Throttle[T any](ctx context.Context, in <-chan immutable.Chain[T], interval time.Duration, opts ...Option) <-chan immutable.Chain[[]T] {
+	if err := positive("interval", interval); err != nil {
+		return failed(ctx, in, newOptions(opts), err)
+	}
+	return runWindow(ctx, in, newOptions(opts), 0, func(w *windower[T], c immutable.Chain[T]) bool {
+		w.add(c)
+		if w.armed {
+			return true
+		}
+		w.arm(interval)
+		return w.flush()
+	}, func(w *windower[T]) bool {
+		if len(w.pending) == 0 {
+			return true
+		}
+		w.arm(interval)
+		return w.flush()
+	})
+}
+
+// windower holds the state of a windowing stage, owned by its goroutine.
+type windower[T any] struct {
+	ctx     context.Context
+	out     chan<- immutable.Chain[[]T]
+	clock   Clock
+	timer   Timer
+	armed   bool
+	pending []immutable.Chain[T]
+	times   []time.Time
+}
+
+// runWindow starts a windowing stage, calling onValue for every successful chain received from in
+// and onTimer when the armed timer fires. A false return stops the stage.
+// With a positive start, the timer is armed to fire after start when the stage starts.
+func runWindow[T any](ctx context.Context, in <-chan immutable.Chain[T], o options, start time.Duration, onValue func(*windower[T], immutable.Chain[T]) bool, onTimer func(*windower[T]) bool) <-chan immutable.Chain[[]T] {
+	out := make(chan immutable.Chain[[]T], o.buffer)
+	w := &windower[T]{ctx: ctx, out: out, clock: o.clock}
+	go func() {
+		defer close(out)
+		defer w.disarm()
+		if start > 0 {
+			w.arm(start)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case c, ok := <-in:
+				if !ok {
+					w.flush()
+					return
+				}
+				if c.IsFailure() {
+					if !w.emit([]immutable.Chain[T]{c}) {
+						return
+					}
+					continue
+				}
+				if !onValue(w, c) {
+					return
+				}
+			case <-w.fired():
+				w.armed = false
+				if !onTimer(w) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// add appends c to the pending values and returns the time it was received at.
+func (w *windower[T]) add(c immutable.Chain[T]) time.Time {
+	now := w.clock.Now()
+	w.pending = append(w.pending, c)
+	w.times = append(w.times, now)
+	return now
+}
+
+// evict drops the pending values received before cutoff.
+func (w *windower[T]) evict(cutoff time.Time) {
+	i := 0
+	for i < len(w.times) && w.times[i].Before(cutoff) {
+		i++
+	}
+	w.pending = slices.Delete(w.pending, 0, i)
+	w.times = slices.Delete(w.times, 0, i)
+}
+
+// flush emits the pending values and clears them.
+func (w *windower[T]) flush() bool {
+	ok := w.emit(w.pending)
+	w.pending, w.times = nil, nil
+	return ok
+}
+
+// emit sends chains as a single Chain[[]T], skipping an empty group.
+func (w *windower[T]) emit(chains []immutable.Chain[T]) bool {
+	if len(chains) == 0 {
+		return true
+	}
+	return send(w.ctx, w.out, immutable.Collect(slices.Values(chains)))
+}
+
+// arm (re)starts the timer so that it fires after d.
+func (w *windower[T]) arm(d time.Duration) {
+	if w.timer == nil {
+		w.timer = w.clock.NewTimer(d)
+	} else {
+		w.timer.Reset(d)
+	}
+	w.armed = true
+}
+
+func (w *windower[T]) disarm() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.armed = false
+}
+
+// fired returns the channel of the armed timer, or nil, which blocks forever, if none is armed.
+func (w *windower[T]) fired() <-chan time.Time {
+	if !w.armed {
+		return nil
+	}
+	return w.timer.C()
+}
+
+// positive reports ErrInvalidDuration if d, the argument called name, is not positive.
+func positive(name string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%w: %s %v", ErrInvalidDuration, name, d)
+	}
+	return nil
+}
+
+// failed starts a stage that emits a single chain failed with err, then drains in until it is closed,
+// so that the stages upstream are not blocked.
+func failed[T any](ctx context.Context, in <-chan immutable.Chain[T], o options, err error) <-chan immutable.Chain[[]T] {
+	out := make(chan immutable.Chain[[]T], o.buffer)
+	go func() {
+		defer close(out)
+		if !send(ctx, out, immutable.Wrap[[]T](nil).WithError(err)) {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-in:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,223 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func next[T any](t *testing.T, out <-chan immutable.Chain[[]T]) ([]T, error) {
+	t.Helper()
+	select {
+	case c, ok := <-out:
+		if !ok {
+			t.Fatal("output closed")
+		}
+		return c.Result()
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a window")
+	}
+	return nil, nil
+}
+
+func expectWindow[T comparable](t *testing.T, out <-chan immutable.Chain[[]T], want ...T) {
+	t.Helper()
+	got, err := next(t, out)
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v, %v", want, got, err)
+	}
+}
+
+func expectClosed[T any](t *testing.T, out <-chan immutable.Chain[[]T]) {
+	t.Helper()
+	select {
+	case c, ok := <-out:
+		if ok {
+			t.Fatalf("expected output to be closed, got %v", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the output to close")
+	}
+}
+
+func TestBatch_Size(t *testing.T) {
+	clock := NewManualClock(epoch)
+	in := make(chan immutable.Chain[int])
+	out := Batch(context.Background(), in, 2, time.Minute, WithClock(clock), WithBuffer(2))
+
+	for i := range 5 {
+		in <- immutable.Wrap(i)
+	}
+	expectWindow(t, out, 0, 1)
+	expectWindow(t, out, 2, 3)
+	close(in)
+	expectWindow(t, out, 4)
+	expectClosed(t, out)
+}
+
+func TestBatch_MaxWait(t *testing.T) {
+	clock := NewManualClock(epoch)
+	in := make(chan immutable.Chain[int])
+	out := Batch(context.Background(), in, 10, time.Second, WithClock(clock))
+
+	in <- immutable.Wrap(1)
+	in <- immutable.Wrap(2)
+	clock.WaitForTimers(1)
+	clock.Advance(time.Second)
+	expectWindow(t, out, 1, 2)
+
+	close(in)
+	expectClosed(t, out)
+}
+
+func TestBatch_ErrorsForwarded(t *testing.T) {
+	errBoom := errors.New("boom")
+	in := make(chan immutable.Chain[int])
+	out := Batch(context.Background(), in, 2, 0)
+
+	in <- immutable.Wrap(1)
+	in <- immutable.Wrap(0).WithError(errBoom)
+	if _, err := next(t, out); !errors.Is(err, errBoom) {
+		t.Fatalf("expected the failure to be forwarded, got %v", err)
+	}
+	in <- immutable.Wrap(2)
+	expectWindow(t, out, 1, 2)
+	close(in)
+	expectClosed(t, out)
+}
+
+func TestTumbling(t *testing.T) {
+	clock := NewManualClock(epoch)
+	in := make(chan immutable.Chain[string])
+	out := Tumbling(context.Background(), in, time.Minute, WithClock(clock))
+
+	clock.WaitForTimers(1)
+	in <- immutable.Wrap("a")
+	in <- immutable.Wrap("b")
+	clock.Advance(time.Minute)
+	expectWindow(t, out, "a", "b")
+
+	// An empty window emits nothing; the next value lands in the third window.
+	clock.WaitForDeadline(epoch.Add(2 * time.Minute))
+	clock.Advance(time.Minute)
+	clock.WaitForDeadline(epoch.Add(3 * time.Minute))
+	in <- immutable.Wrap("c")
+	clock.Advance(time.Minute)
+	expectWindow(t, out, "c")
+
+	close(in)
+	expectClosed(t, out)
+}
+
+// readClock counts the reads of a ManualClock, so that tests know when a stage has timestamped a value.
+type readClock struct {
+	*ManualClock
+	reads atomic.Int32
+}
+
+func (c *readClock) Now() time.Time {
+	defer c.reads.Add(1)
+	return c.ManualClock.Now()
+}
+
+// send delivers c to in and waits until the stage has read the clock to timestamp it.
+func (c *readClock) send(in chan<- immutable.Chain[int], v immutable.Chain[int]) {
+	n := c.reads.Load()
+	in <- v
+	for c.reads.Load() == n {
+		runtime.Gosched()
+	}
+}
+
+func TestSliding(t *testing.T) {
+	clock := &readClock{ManualClock: NewManualClock(epoch)}
+	in := make(chan immutable.Chain[int])
+	out := Sliding(context.Background(), in, 2*time.Second, time.Second, WithClock(clock))
+
+	clock.WaitForTimers(1)
+	clock.send(in, immutable.Wrap(1))
+	clock.Advance(time.Second)
+	expectWindow(t, out, 1)
+
+	clock.WaitForDeadline(epoch.Add(2 * time.Second))
+	clock.send(in, immutable.Wrap(2))
+	clock.Advance(time.Second)
+	expectWindow(t, out, 1, 2)
+
+	clock.WaitForDeadline(epoch.Add(3 * time.Second))
+	clock.Advance(time.Second)
+	expectWindow(t, out, 2)
+}
+
+func TestDebounce(t *testing.T) {
+	clock := NewManualClock(epoch)
+	in := make(chan immutable.Chain[int])
+	out := Debounce(context.Background(), in, time.Second, WithClock(clock))
+
+	in <- immutable.Wrap(1)
+	clock.WaitForDeadline(epoch.Add(time.Second))
+	clock.Advance(500 * time.Millisecond)
+	in <- immutable.Wrap(2)
+	clock.WaitForDeadline(epoch.Add(1500 * time.Millisecond))
+	clock.Advance(500 * time.Millisecond)
+	in <- immutable.Wrap(3)
+	clock.WaitForDeadline(epoch.Add(2 * time.Second))
+	clock.Advance(time.Second)
+	expectWindow(t, out, 1, 2, 3)
+
+	close(in)
+	expectClosed(t, out)
+}
+
+func TestThrottle(t *testing.T) {
+	clock := NewManualClock(epoch)
+	in := make(chan immutable.Chain[int])
+	out := Throttle(context.Background(), in, time.Second, WithClock(clock))
+
+	in <- immutable.Wrap(1)
+	expectWindow(t, out, 1)
+	in <- immutable.Wrap(2)
+	in <- immutable.Wrap(3)
+	clock.Advance(time.Second)
+	expectWindow(t, out, 2, 3)
+
+	// A quiet interval closes the throttle window.
+	clock.WaitForDeadline(epoch.Add(2 * time.Second))
+	clock.Advance(time.Second)
+	in <- immutable.Wrap(4)
+	expectWindow(t, out, 4)
+
+	close(in)
+	expectClosed(t, out)
+}
+
+func TestWindow_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan immutable.Chain[int])
+	out := Debounce(ctx, in, time.Hour)
+
+	in <- immutable.Wrap(1)
+	cancel()
+	expectClosed(t, out)
+}
+
+func TestWindow_InvalidDuration(t *testing.T) {
+	in := make(chan immutable.Chain[int])
+	out := Sliding(context.Background(), in, time.Second, 0)
+
+	if _, err := next(t, out); !errors.Is(err, ErrInvalidDuration) {
+		t.Fatalf("expected ErrInvalidDuration, got %v", err)
+	}
+	// The input is drained, so upstream stages are not blocked.
+	in <- immutable.Wrap(1)
+	close(in)
+	expectClosed(t, out)
+}
@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists checkpoints, keyed by run ID and step name.
+type Store interface {
+	// Load returns the checkpoint of a step, and false if there is none.
+	Load(runID, step string) ([]byte, bool, error)
+	// Save stores the checkpoint of a step, replacing any previous one.
+	Save(runID, step string, data []byte) error
+	// Delete removes every checkpoint of a run.
+	Delete(runID string) error
+}
+
+// FileStore keeps one file per checkpoint, under a directory per run.
+// Checkpoints are written to a temporary file first and renamed, so a crash never leaves a partial one,
+// and the directories are synced so that the rename itself survives a crash.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore keeping its checkpoints under dir, created on first use.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) runDir(runID string) string {
+	return filepath.Join(s.dir, url.PathEscape(runID))
+}
+
+func (s *FileStore) path(runID, step string) string {
+	return filepath.Join(s.runDir(runID), url.PathEscape(step)+".ckpt")
+}
+
+func (s *FileStore) Load(runID, step string) ([]byte, bool, error) {
+	data, err := os.ReadFile(s.path(runID, step))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *FileStore) Save(runID, step string, data []byte) error {
+	dir := s.runDir(runID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(runID, step)); err != nil {
+		return err
+	}
+	// Sync the parent too, in case the run directory was just created.
+	return errors.Join(syncDir(dir), syncDir(s.dir))
+}
+
+// syncDir flushes the entries of dir to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
+
+func (s *FileStore) Delete(runID string) error {
+	return os.RemoveAll(s.runDir(runID))
+}
+
+// MemoryStore keeps checkpoints in memory, for tests and runs that need no durability.
+type MemoryStore struct {
+	mu   sync.Mutex
+	runs map[string]map[string][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{runs: make(map[string]map[string][]byte)}
+}
+
+func (s *MemoryStore) Load(runID, step string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.runs[runID][step]
+	return data, ok, nil
+}
+
+func (s *MemoryStore) Save(runID, step string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.runs[runID] == nil {
+		s.runs[runID] = make(map[string][]byte)
+	}
+	s.runs[runID][step] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *MemoryStore) Delete(runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.runs, runID)
+	return nil
+}
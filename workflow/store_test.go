@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+
+	if _, ok, err := s.Load("run/1", "step"); ok || err != nil {
+		t.Fatalf("expected no checkpoint, got %v, %v", ok, err)
+	}
+
+	if err := s.Save("run/1", "step", []byte("v1")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := s.Save("run/1", "step", []byte("v2")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	data, ok, err := s.Load("run/1", "step")
+	if !ok || err != nil || string(data) != "v2" {
+		t.Fatalf("expected v2, got %q, %v, %v", data, ok, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected the run ID to be escaped into a single directory, got %v", entries)
+	}
+
+	if err := s.Delete("run/1"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok, _ := s.Load("run/1", "step"); ok {
+		t.Fatal("expected the checkpoint to be deleted")
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	for _, c := range []Codec{JSONCodec{}, GobCodec{}} {
+		data, err := c.Marshal(Report{Lines: []string{"a"}, Total: 1})
+		if err != nil {
+			t.Fatalf("%T: unexpected error %v", c, err)
+		}
+		var r Report
+		if err := c.Unmarshal(data, &r); err != nil || r.Total != 1 || r.Lines[0] != "a" {
+			t.Fatalf("%T: unexpected round trip %+v, %v", c, r, err)
+		}
+	}
+}
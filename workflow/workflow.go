@@ -0,0 +1,185 @@
+// Package workflow makes immutable.Chain pipelines durable.
+//
+// A Run checkpoints the output of every named step it completes. When a run with the same ID
+// is started again, for instance after a crash, the completed steps are not executed again:
+// their outputs are loaded from the store instead, and the pipeline resumes at the first missing step.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+var (
+	// ErrDuplicateStep is reported when a step name is used twice in the same run.
+	ErrDuplicateStep = errors.New("duplicate step")
+	// ErrNoStore is returned by New when no store was given and the default one cannot be located.
+	ErrNoStore = errors.New("no checkpoint store")
+)
+
+// CheckpointError is the error of a step whose checkpoint could not be loaded, decoded, encoded or saved.
+type CheckpointError struct {
+	RunID string
+	Step  string
+	Err   error
+}
+
+func (e *CheckpointError) Error() string {
+	return fmt.Sprintf("workflow %q: checkpoint of step %q: %v", e.RunID, e.Step, e.Err)
+}
+
+func (e *CheckpointError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a Run.
+type Option func(*Run)
+
+// WithStore sets the store of the checkpoints, see New for the default.
+func WithStore(s Store) Option {
+	return func(r *Run) {
+		if s != nil {
+			r.store = s
+		}
+	}
+}
+
+// WithCodec sets the codec of the checkpoints, JSONCodec by default.
+func WithCodec(c Codec) Option {
+	return func(r *Run) {
+		if c != nil {
+			r.codec = c
+		}
+	}
+}
+
+// Run is one execution of a workflow, identified by its ID. It is safe for concurrent use.
+type Run struct {
+	id    string
+	store Store
+	codec Codec
+
+	mu    sync.Mutex
+	steps map[string]bool
+}
+
+// New returns the run identified by id, resuming it if checkpoints exist for it in the store.
+// The store defaults to a FileStore under go-fp/workflow in the user cache directory, see os.UserCacheDir;
+// if that directory cannot be determined, New fails with ErrNoStore unless WithStore is given.
+func New(id string, opts ...Option) (*Run, error) {
+	r := &Run{
+		id:    id,
+		codec: JSONCodec{},
+		steps: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(r)
+		}
+	}
+	if r.store == nil {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNoStore, err)
+		}
+		r.store = NewFileStore(filepath.Join(dir, "go-fp", "workflow"))
+	}
+	return r, nil
+}
+
+// ID returns the ID of the run.
+func (r *Run) ID() string {
+	return r.id
+}
+
+// Clear deletes the checkpoints of the run, typically once the whole workflow succeeded.
+func (r *Run) Clear() error {
+	return r.store.Delete(r.id)
+}
+
+// Start begins a pipeline with the step name, calling fn unless the step is already checkpointed.
+// The options, such as immutable.WithPanicSafe, apply to the returned chain and the steps following it.
+func Start[T any](r *Run, name string, fn func() (T, error), opts ...immutable.Option) immutable.Chain[T] {
+	return Then(r, name, immutable.Wrap(*new(T), opts...), func(T) (T, error) {
+		if fn == nil {
+			return *new(T), immutable.ErrNilFunc
+		}
+		return fn()
+	})
+}
+
+// Then works like c.Then(f) for the step name.
+// If the step is already checkpointed for the run, f is skipped and the checkpointed value is used;
+// otherwise the output of f is checkpointed once it succeeds.
+// A failed chain is returned unchanged, without recording the step.
+func Then[T any](r *Run, name string, c immutable.Chain[T], f func(T) (T, error)) immutable.Chain[T] {
+	if c.IsFailure() {
+		return c
+	}
+	v, ok, err := load[T](r, name)
+	if err != nil {
+		return c.WithError(err)
+	}
+	if ok {
+		return c.Then(func(T) (T, error) { return v, nil })
+	}
+	return c.Then(f).Then(func(out T) (T, error) {
+		return out, save(r, name, out)
+	})
+}
+
+// Bind works like immutable.Bind(c, f) for the step name, checkpointing its output like Then.
+func Bind[T, U any](r *Run, name string, c immutable.Chain[T], f func(T) immutable.Chain[U]) immutable.Chain[U] {
+	if c.IsFailure() {
+		return immutable.Bind(c, f)
+	}
+	v, ok, err := load[U](r, name)
+	if err != nil {
+		return immutable.Bind(c, func(T) immutable.Chain[U] {
+			return immutable.Wrap(v).WithError(err)
+		})
+	}
+	if ok {
+		return immutable.Bind(c, func(T) immutable.Chain[U] { return immutable.Wrap(v) })
+	}
+	return immutable.Bind(c, f).Then(func(out U) (U, error) {
+		return out, save(r, name, out)
+	})
+}
+
+// load claims the step name for the run and returns its checkpointed value, if any.
+func load[T any](r *Run, name string) (T, bool, error) {
+	var v T
+	r.mu.Lock()
+	dup := r.steps[name]
+	r.steps[name] = true
+	r.mu.Unlock()
+	if dup {
+		return v, false, &CheckpointError{RunID: r.id, Step: name, Err: ErrDuplicateStep}
+	}
+
+	data, ok, err := r.store.Load(r.id, name)
+	if err == nil && ok {
+		err = r.codec.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return v, false, &CheckpointError{RunID: r.id, Step: name, Err: err}
+	}
+	return v, ok, nil
+}
+
+func save[T any](r *Run, name string, v T) error {
+	data, err := r.codec.Marshal(v)
+	if err == nil {
+		err = r.store.Save(r.id, name, data)
+	}
+	if err != nil {
+		return &CheckpointError{RunID: r.id, Step: name, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	immutable "github.com/KeibiSoft/go-fp/immutable"
+)
+
+type Report struct {
+	Lines []string
+	Total int
+}
+
+func newRun(t *testing.T, id string, opts ...Option) *Run {
+	t.Helper()
+	r, err := New(id, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// pipeline runs a three step workflow, counting the executions of each step.
+// The load step fails when failLoad is set, simulating a crash midway.
+func pipeline(r *Run, calls map[string]int, failLoad bool) immutable.Chain[int] {
+	fetched := Start(r, "fetch", func() (string, error) {
+		calls["fetch"]++
+		return "a,b,c", nil
+	})
+	parsed := Bind(r, "parse", fetched, func(s string) immutable.Chain[Report] {
+		calls["parse"]++
+		lines := strings.Split(s, ",")
+		return immutable.Wrap(Report{Lines: lines, Total: len(lines)})
+	})
+	return Bind(r, "load", parsed, func(rep Report) immutable.Chain[int] {
+		calls["load"]++
+		if failLoad {
+			return immutable.Wrap(0).WithError(errors.New("database unavailable"))
+		}
+		return immutable.Wrap(rep.Total)
+	})
+}
+
+func TestRun_Resume(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	calls := map[string]int{}
+
+	if _, err := pipeline(newRun(t, "run-1", WithStore(store)), calls, true).Result(); err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+
+	got, err := pipeline(newRun(t, "run-1", WithStore(store)), calls, false).Result()
+	if err != nil || got != 3 {
+		t.Fatalf("expected 3, got %d, %v", got, err)
+	}
+	if calls["fetch"] != 1 || calls["parse"] != 1 || calls["load"] != 2 {
+		t.Fatalf("expected only the failed step to run again, got %v", calls)
+	}
+
+	// A completed run is fully replayed from its checkpoints.
+	if got, err := pipeline(newRun(t, "run-1", WithStore(store)), calls, true).Result(); err != nil || got != 3 {
+		t.Fatalf("expected the checkpointed result, got %d, %v", got, err)
+	}
+
+	// Another run ID starts from scratch.
+	pipeline(newRun(t, "run-2", WithStore(store)), calls, false)
+	if calls["fetch"] != 2 {
+		t.Fatalf("expected a separate run to execute its steps, got %v", calls)
+	}
+}
+
+func TestRun_Clear(t *testing.T) {
+	store := NewMemoryStore()
+	calls := map[string]int{}
+	r := newRun(t, "run", WithStore(store), WithCodec(GobCodec{}))
+
+	pipeline(r, calls, false)
+	if err := r.Clear(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	pipeline(newRun(t, "run", WithStore(store), WithCodec(GobCodec{})), calls, false)
+	if calls["fetch"] != 2 {
+		t.Fatalf("expected cleared steps to run again, got %v", calls)
+	}
+}
+
+func TestThen_DuplicateStep(t *testing.T) {
+	r := newRun(t, "run", WithStore(NewMemoryStore()))
+	inc := func(v int) (int, error) { return v + 1, nil }
+
+	c := Then(r, "inc", immutable.Wrap(1), inc)
+	_, err := Then(r, "inc", c, inc).Result()
+	if !errors.Is(err, ErrDuplicateStep) {
+		t.Fatalf("expected ErrDuplicateStep, got %v", err)
+	}
+}
+
+func TestThen_CheckpointErrors(t *testing.T) {
+	store := NewMemoryStore()
+	store.Save("run", "parse", []byte("not json"))
+
+	_, err := Then(newRun(t, "run", WithStore(store)), "parse", immutable.Wrap(1), func(v int) (int, error) { return v, nil }).Result()
+	var cerr *CheckpointError
+	if !errors.As(err, &cerr) || cerr.Step != "parse" {
+		t.Fatalf("expected a CheckpointError for a corrupt checkpoint, got %v", err)
+	}
+
+	_, err = Then(newRun(t, "run", WithStore(store)), "chan", immutable.Wrap(make(chan int)), func(c chan int) (chan int, error) { return c, nil }).Result()
+	if !errors.As(err, &cerr) || cerr.Step != "chan" {
+		t.Fatalf("expected a CheckpointError for an unencodable output, got %v", err)
+	}
+}
+
+func TestThen_SkipsFailedChain(t *testing.T) {
+	store := NewMemoryStore()
+	errBoom := errors.New("boom")
+
+	_, err := Then(newRun(t, "run", WithStore(store)), "step", immutable.Wrap(1).WithError(errBoom), func(v int) (int, error) { return v, nil }).Result()
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected the chain error, got %v", err)
+	}
+	if _, ok, _ := store.Load("run", "step"); ok {
+		t.Fatal("expected no checkpoint for a skipped step")
+	}
+}
+
+func TestNew_DefaultStore(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv("HOME", cache)
+
+	r := newRun(t, "run")
+	if _, err := Start(r, "fetch", func() (int, error) { return 1, nil }).Result(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dir, _ := os.UserCacheDir()
+	if _, err := os.Stat(filepath.Join(dir, "go-fp", "workflow", "run", "fetch.ckpt")); err != nil {
+		t.Fatalf("expected the checkpoint in the user cache directory: %v", err)
+	}
+
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		t.Setenv("XDG_CACHE_HOME", "")
+		t.Setenv("HOME", "")
+		if _, err := New("run"); !errors.Is(err, ErrNoStore) {
+			t.Fatalf("expected ErrNoStore without a cache directory, got %v", err)
+		}
+		if _, err := New("run", WithStore(NewMemoryStore())); err != nil {
+			t.Fatalf("expected an explicit store to be accepted, got %v", err)
+		}
+	}
+}
+
+func TestStart_Options(t *testing.T) {
+	r := newRun(t, "run", WithStore(NewMemoryStore()))
+	c := Start(r, "fetch", func() (int, error) { return 1, nil }, immutable.WithPanicSafe())
+
+	_, err := Then(r, "boom", c, func(int) (int, error) { panic("boom") }).Result()
+	var pe *immutable.PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected the panic-safe option to carry over, got %v", err)
+	}
+}